@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime"
+	"time"
+
+	"github.com/grsubramanian/go-playground/pkg/concurrency_in_go_book/chapter4"
+)
+
+func main() {
+
+	baseline := runtime.NumGoroutine()
+
+	checkCancelled()
+	checkGoroutines(baseline)
+
+	checkCompleted()
+	checkGoroutines(baseline)
+
+	checkDeadline()
+	checkGoroutines(baseline)
+
+	fmt.Println("All checks passed")
+}
+
+// Builds pipelines out of every stage, reads a little from each, and then cancels.
+// Every stage must stop with context.Canceled.
+func checkCancelled() {
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ones, repeatErr := chapter4.RepeatContext(ctx, 1)
+	twos, _ := chapter4.RepeatContext(ctx, 2)
+	fannedIn, fanInErr := chapter4.FanInContext(ctx, ones, twos)
+	taken, takeErr := chapter4.TakeContext(ctx, fannedIn, 1000)
+	left, right, teeErr := chapter4.TeeContext(ctx, taken)
+	orDone, orDoneErr := chapter4.OrDoneContext(ctx, left)
+
+	// A stream of streams that never ends.
+	chanStream := make(chan (<-chan int))
+	go func() {
+		defer close(chanStream)
+		for {
+			s, _ := chapter4.RepeatContext(ctx, 3)
+			select {
+			case <-ctx.Done():
+				return
+			case chanStream <- s:
+			}
+		}
+	}()
+	bridged, bridgeErr := chapter4.BridgeContext(ctx, chanStream)
+
+	// Channels that never fire.
+	never := make(chan interface{})
+	or, orErr := chapter4.OrContext(ctx, never, never, never, never, never)
+
+	for i := 0; i < 10; i++ {
+		<-orDone
+		<-right
+		<-bridged
+	}
+
+	cancel()
+
+	for _, c := range []<-chan int{ones, twos, fannedIn, taken, left, right, orDone, bridged} {
+		drain(c)
+	}
+	<-or
+
+	errs := map[string]func() error{
+		"RepeatContext": repeatErr,
+		"FanInContext":  fanInErr,
+		"TakeContext":   takeErr,
+		"TeeContext":    teeErr,
+		"OrDoneContext": orDoneErr,
+		"BridgeContext": bridgeErr,
+		"OrContext":     orErr,
+	}
+	for name, err := range errs {
+		if err() != context.Canceled {
+			log.Fatalf("validation failed: %s stopped with %v, expected %v", name, err(), context.Canceled)
+		}
+	}
+}
+
+// Stages that run to completion must stop with no error.
+func checkCompleted() {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ones, _ := chapter4.RepeatContext(ctx, 1)
+	taken, takeErr := chapter4.TakeContext(ctx, ones, 5)
+	orDone, orDoneErr := chapter4.OrDoneContext(ctx, taken)
+
+	sum := 0
+	for v := range orDone {
+		sum += v
+	}
+	if sum != 5 {
+		log.Fatalf("validation failed: sum is %d, expected 5", sum)
+	}
+	if takeErr() != nil || orDoneErr() != nil {
+		log.Fatalf("validation failed: completed stages stopped with %v and %v, expected no error", takeErr(), orDoneErr())
+	}
+
+	fired := make(chan interface{})
+	close(fired)
+	or, orErr := chapter4.OrContext(ctx, make(chan interface{}), fired)
+	<-or
+	if orErr() != nil {
+		log.Fatalf("validation failed: OrContext stopped with %v, expected no error", orErr())
+	}
+}
+
+// The error is whatever the context reports, here its deadline.
+func checkDeadline() {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	never := make(chan int)
+	orDone, orDoneErr := chapter4.OrDoneContext(ctx, never)
+	drain(orDone)
+
+	if orDoneErr() != context.DeadlineExceeded {
+		log.Fatalf("validation failed: OrDoneContext stopped with %v, expected %v", orDoneErr(), context.DeadlineExceeded)
+	}
+}
+
+func drain[T any](c <-chan T) {
+	for range c {
+	}
+}
+
+// Waits for the number of goroutines to come back down to the baseline, failing if it doesn't
+// within a second. Goroutines take a little while to exit after their channels close.
+func checkGoroutines(baseline int) {
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			n := runtime.Stack(buf, true)
+			log.Fatalf("validation failed: %d goroutines running, expected %d\n%s", runtime.NumGoroutine(), baseline, buf[:n])
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
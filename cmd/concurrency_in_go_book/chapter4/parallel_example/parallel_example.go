@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/grsubramanian/go-playground/pkg/concurrency_in_go_book/chapter4"
+)
+
+var (
+	seed     int64
+	numItems int
+	workers  int
+)
+
+func init() {
+	flag.Int64Var(&seed, "seed", time.Now().Unix(), "seed for repeatable testing")
+	flag.IntVar(&numItems, "N", 200, "number of items to map")
+	flag.IntVar(&workers, "workers", 8, "number of goroutines to map on")
+	flag.Parse()
+}
+
+func main() {
+
+	log.Printf("Seed is %d", seed)
+
+	baseline := runtime.NumGoroutine()
+
+	r := rand.New(rand.NewSource(seed))
+	delays := make([]time.Duration, numItems)
+	for i := range delays {
+		delays[i] = time.Duration(r.Intn(2000)) * time.Microsecond
+	}
+
+	// Sleeps for a random while, so that results come back out of order.
+	square := func(i int) int {
+		time.Sleep(delays[i])
+		return i * i
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan interface{})
+	defer close(done)
+
+	start := time.Now()
+	ordered, orderedErr := chapter4.ParallelMap(ctx, chapter4.IntStream(done, upTo(numItems)...), workers, square)
+	var got []int
+	for v := range ordered {
+		got = append(got, v)
+	}
+	log.Printf("Ordered map of %d items on %d workers took %v", numItems, workers, time.Since(start))
+
+	if orderedErr() != nil {
+		log.Fatalf("validation failed: ParallelMap stopped with %v", orderedErr())
+	}
+	for i, v := range got {
+		if v != i*i {
+			log.Fatalf("validation failed: result %d is %d, expected %d", i, v, i*i)
+		}
+	}
+	if len(got) != numItems {
+		log.Fatalf("validation failed: got %d results, expected %d", len(got), numItems)
+	}
+
+	start = time.Now()
+	unordered, unorderedErr := chapter4.ParallelMapUnordered(ctx, chapter4.IntStream(done, upTo(numItems)...), workers, square)
+	got = got[:0]
+	for v := range unordered {
+		got = append(got, v)
+	}
+	log.Printf("Unordered map of %d items on %d workers took %v", numItems, workers, time.Since(start))
+
+	if unorderedErr() != nil {
+		log.Fatalf("validation failed: ParallelMapUnordered stopped with %v", unorderedErr())
+	}
+	sort.Ints(got)
+	for i, v := range got {
+		if v != i*i {
+			log.Fatalf("validation failed: sorted result %d is %d, expected %d", i, v, i*i)
+		}
+	}
+
+	checkCancelled(square)
+	checkGoroutines(baseline)
+
+	fmt.Println("All checks passed")
+}
+
+// Cancels both variants part way through. Both must stop with context.Canceled, without leaking.
+func checkCancelled(fn func(int) int) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	endless, _ := chapter4.RepeatContext(ctx, 0)
+	ordered, orderedErr := chapter4.ParallelMap(ctx, endless, workers, fn)
+	unordered, unorderedErr := chapter4.ParallelMapUnordered(ctx, endless, workers, fn)
+
+	for i := 0; i < 10; i++ {
+		<-ordered
+		<-unordered
+	}
+	cancel()
+
+	for range ordered {
+	}
+	for range unordered {
+	}
+
+	if orderedErr() != context.Canceled || unorderedErr() != context.Canceled {
+		log.Fatalf("validation failed: cancelled maps stopped with %v and %v, expected %v", orderedErr(), unorderedErr(), context.Canceled)
+	}
+}
+
+func upTo(n int) []int {
+	vals := make([]int, n)
+	for i := range vals {
+		vals[i] = i
+	}
+	return vals
+}
+
+// Waits for the number of goroutines to come back down to the baseline, failing if it doesn't
+// within a second. Goroutines take a little while to exit after their channels close.
+func checkGoroutines(baseline int) {
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			n := runtime.Stack(buf, true)
+			log.Fatalf("validation failed: %d goroutines running, expected %d\n%s", runtime.NumGoroutine(), baseline, buf[:n])
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
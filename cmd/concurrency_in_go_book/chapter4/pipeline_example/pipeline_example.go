@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/grsubramanian/go-playground/pkg/concurrency_in_go_book/chapter4"
+)
+
+func main() {
+
+	done := make(chan interface{})
+	defer close(done)
+
+	// Squares of the odd numbers from 1 upwards, skipping the first, while they're below 200.
+	counter := 0
+	naturals := chapter4.RepeatFn(done, func() int {
+		counter++
+		return counter
+	})
+	odds := chapter4.Filter(done, naturals, func(i int) bool { return i%2 == 1 })
+	squares := chapter4.Map(done, odds, func(i int) int { return i * i })
+	small := chapter4.TakeWhile(done, chapter4.Skip(done, squares, 1), func(i int) bool { return i < 200 })
+
+	var got []int
+	for i := range small {
+		got = append(got, i)
+	}
+	checkEqual(got, []int{9, 25, 49, 81, 121, 169})
+
+	// Batches of strings.
+	strs := chapter4.Map(done, chapter4.Generate(done, 1, 2, 3, 4, 5), strconv.Itoa)
+	var batches [][]string
+	for b := range chapter4.Batch(done, strs, 2) {
+		batches = append(batches, b)
+	}
+	if fmt.Sprint(batches) != "[[1 2] [3 4] [5]]" {
+		log.Fatalf("validation failed: batches are %v", batches)
+	}
+
+	// Sum of the first 10 values of a repeating sequence.
+	sum := <-chapter4.Reduce(done, chapter4.Take(done, chapter4.Repeat(done, 1, 2, 3), 10), 0, func(acc int, i int) int {
+		return acc + i
+	})
+	if sum != 19 {
+		log.Fatalf("validation failed: sum is %d, expected 19", sum)
+	}
+
+	// Pre-empting a reduction closes its output without a result.
+	stop := make(chan interface{})
+	reduced := chapter4.Reduce(stop, chapter4.Repeat(stop, 1), 0, func(acc int, i int) int { return acc + i })
+	close(stop)
+	if v, ok := <-reduced; ok {
+		log.Fatalf("validation failed: pre-empted reduction yielded %d", v)
+	}
+
+	fmt.Println("Squares :", got)
+	fmt.Println("Batches :", batches)
+	fmt.Println("Sum :", sum)
+}
+
+func checkEqual(got []int, expected []int) {
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		log.Fatalf("validation failed: got %v, expected %v", got, expected)
+	}
+}
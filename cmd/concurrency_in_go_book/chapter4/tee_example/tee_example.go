@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/grsubramanian/go-playground/pkg/concurrency_in_go_book/chapter4"
+)
+
+var n = 100
+
+func main() {
+
+	checkBlocking()
+	checkDropping()
+
+	fmt.Println("All checks passed")
+}
+
+// Every blocking output gets every value, in order, even when read from a single goroutine
+// in a different order to the one they were created in.
+func checkBlocking() {
+
+	done := make(chan interface{})
+	defer close(done)
+
+	outs := chapter4.TeeN(done, chapter4.IntStream(done, upTo(n)...), 3)
+
+	got := make([][]int, len(outs))
+	for i := 0; i < n; i++ {
+		for j := len(outs) - 1; j >= 0; j-- {
+			got[j] = append(got[j], <-outs[j])
+		}
+	}
+
+	for j, c := range outs {
+		if _, ok := <-c; ok {
+			log.Fatalf("validation failed: output %d still open after the input closed", j)
+		}
+		checkEqual(fmt.Sprintf("output %d", j), got[j], upTo(n))
+	}
+}
+
+// A fast consumer gets everything, while consumers that don't read at all until the input
+// is exhausted only get what fit in their buffers.
+func checkDropping() {
+
+	done := make(chan interface{})
+	defer close(done)
+
+	const buffer = 5
+	outs := chapter4.TeeNWithOutputs(done, chapter4.IntStream(done, upTo(n)...), []chapter4.TeeOutput{
+		{},
+		{Buffer: buffer, Policy: chapter4.DropNewest},
+		{Buffer: buffer, Policy: chapter4.DropOldest},
+		{Policy: chapter4.DropNewest},
+	})
+
+	// The stalled consumers must not hold back the fast one.
+	checkEqual("fast output", drain(outs[0]), upTo(n))
+
+	checkEqual("drop newest output", drain(outs[1]), upTo(buffer))
+	checkEqual("drop oldest output", drain(outs[2]), upTo(n)[n-buffer:])
+
+	// Nobody was ever waiting on the unbuffered dropping output.
+	checkEqual("unbuffered drop newest output", drain(outs[3]), nil)
+}
+
+func drain(c <-chan int) []int {
+	var vals []int
+	for v := range c {
+		vals = append(vals, v)
+	}
+	return vals
+}
+
+func upTo(n int) []int {
+	vals := make([]int, n)
+	for i := range vals {
+		vals[i] = i
+	}
+	return vals
+}
+
+func checkEqual(name string, got []int, expected []int) {
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		log.Fatalf("validation failed: %s yielded %v, expected %v", name, got, expected)
+	}
+}
@@ -6,27 +6,27 @@ import (
 	"sync"
 	"time"
 
-	"github.com/grsubramanian/go-playground/internal"
+	"github.com/grsubramanian/go-playground/pkg/semaphore"
 )
 
 var wg sync.WaitGroup
 
-var a1Done, _ = internal.NewSemaphore(1, 0)
-var b1Done, _ = internal.NewSemaphore(1, 0)
+var a1Done = semaphore.New(0)
+var b1Done = semaphore.New(0)
 
 func doWork(val string) {
 	time.Sleep(time.Duration(rand.Intn(1000)) * time.Millisecond)
 	fmt.Println(val)
 }
 
-func signaller(done internal.Semaphore, val string) {
+func signaller(done *semaphore.Semaphore, val string) {
 	doWork(val)
 
 	// signal.
 	done.Signal()
 }
 
-func waiter(done internal.Semaphore, val string) {
+func waiter(done *semaphore.Semaphore, val string) {
 
 	// wait.
 	done.Wait()
@@ -6,7 +6,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/grsubramanian/go-playground/internal"
+	"github.com/grsubramanian/go-playground/pkg/semaphore"
 )
 
 var wg sync.WaitGroup
@@ -15,7 +15,7 @@ var n = 100
 var count = 0
 var countLock = sync.Mutex{}
 
-var done, _ = internal.NewSemaphore(n, 0)
+var done = semaphore.New(0)
 
 func rendezvous() {
 	countLock.Lock()
@@ -6,7 +6,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/grsubramanian/go-playground/internal"
+	"github.com/grsubramanian/go-playground/pkg/semaphore"
 )
 
 var wg sync.WaitGroup
@@ -16,8 +16,8 @@ var count = 0
 var allDone = false
 var countLock = sync.Mutex{}
 
-var barrierDone, _ = internal.NewSemaphore(n, 0)
-var barrierReset, _ = internal.NewSemaphore(n, 0)
+var barrierDone = semaphore.New(0)
+var barrierReset = semaphore.New(0)
 
 func barrier() {
 	countLock.Lock()
@@ -6,7 +6,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/grsubramanian/go-playground/internal"
+	"github.com/grsubramanian/go-playground/pkg/semaphore"
 )
 
 var wg sync.WaitGroup
@@ -15,11 +15,11 @@ var wg sync.WaitGroup
 // So, total 2*n folks.
 var n = 10
 
-var previousLeaderDone, _ = internal.NewSemaphore(1, 1)
-var previousFollowerDone, _ = internal.NewSemaphore(1, 1)
+var previousLeaderDone = semaphore.New(1)
+var previousFollowerDone = semaphore.New(1)
 
-var leaderAvailable, _ = internal.NewSemaphore(1, 0)
-var followerAvailable, _ = internal.NewSemaphore(1, 0)
+var leaderAvailable = semaphore.New(0)
+var followerAvailable = semaphore.New(0)
 
 func dance(id int, typ string) {
 	time.Sleep(time.Duration(rand.Intn(1000)) * time.Millisecond)
@@ -6,7 +6,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/grsubramanian/go-playground/internal"
+	"github.com/grsubramanian/go-playground/pkg/semaphore"
 )
 
 var wg sync.WaitGroup
@@ -18,12 +18,12 @@ var n = 10
 var unpairedLeaders = 0
 var unpairedFollowers = 0
 
-var leaderAvailable, _ = internal.NewSemaphore(1, 0)
-var followerAvailable, _ = internal.NewSemaphore(1, 0)
+var leaderAvailable = semaphore.New(0)
+var followerAvailable = semaphore.New(0)
 
-var rendezvous, _ = internal.NewSemaphore(1, 0)
+var rendezvous = semaphore.New(0)
 
-var lock, _ = internal.NewSemaphore(1, 1)
+var lock = semaphore.New(1)
 
 func dance(id int, typ string) {
 	time.Sleep(time.Duration(rand.Intn(1000)) * time.Millisecond)
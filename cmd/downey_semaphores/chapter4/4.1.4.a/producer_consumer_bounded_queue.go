@@ -4,55 +4,29 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/grsubramanian/go-playground/internal"
+	"github.com/grsubramanian/go-playground/pkg/queue"
 )
 
 var wg sync.WaitGroup
 
 var n = 10
 
-var nonEmpty, _ = internal.NewSemaphore(n, 0)
-var nonFull, _ = internal.NewSemaphore(n, n)
-
-var lock = sync.RWMutex{}
-
-var q = internal.NewQueue()
-
-func producerCore(item int) {
-
-	nonFull.Wait()
-
-	lock.Lock()
-	q.Enqueue(item)
-	fmt.Printf("Enqueued item %d\n", item)
-	lock.Unlock()
-
-	nonEmpty.Signal()
-}
-
-func consumerCore() {
-
-	nonEmpty.Wait()
-
-	lock.Lock()
-	item := q.Dequeue()
-	fmt.Printf("Dequeued item %d\n", item)
-	lock.Unlock()
-
-	nonFull.Signal()
-}
+// The producer blocks whenever the consumer falls n items behind.
+var q = queue.NewBlockingQueue[int](n)
 
 func producer() {
 	for i := 0; i < 1000; i++ {
-		producerCore(i)
+		q.Put(i)
+		fmt.Printf("Enqueued item %d\n", i)
 	}
+	q.Close()
 
 	wg.Done()
 }
 
 func consumer() {
-	for i := 0; i < 1000; i++ {
-		consumerCore()
+	for item, ok := q.Take(); ok; item, ok = q.Take() {
+		fmt.Printf("Dequeued item %d\n", item)
 	}
 
 	wg.Done()
@@ -4,50 +4,29 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/grsubramanian/go-playground/internal"
+	"github.com/grsubramanian/go-playground/pkg/queue"
 )
 
 var wg sync.WaitGroup
 
 var n = 100
 
-var nonEmpty, _ = internal.NewSemaphore(n, 0)
-
-var lock = sync.RWMutex{}
-
-var q = internal.NewQueue()
-
-func producerCore(item int) {
-
-	lock.Lock()
-	q.Enqueue(item)
-	fmt.Printf("Enqueued item %d\n", item)
-	lock.Unlock()
-
-	nonEmpty.Signal()
-}
-
-func consumerCore() {
-
-	nonEmpty.Wait()
-
-	lock.Lock()
-	item := q.Dequeue()
-	fmt.Printf("Dequeued item %d\n", item)
-	lock.Unlock()
-}
+// Big enough to hold everything the producer produces, so the producer never blocks.
+var q = queue.NewBlockingQueue[int](n)
 
 func producer() {
 	for i := 0; i < n; i++ {
-		producerCore(i)
+		q.Put(i)
+		fmt.Printf("Enqueued item %d\n", i)
 	}
+	q.Close()
 
 	wg.Done()
 }
 
 func consumer() {
-	for i := 0; i < n; i++ {
-		consumerCore()
+	for item, ok := q.Take(); ok; item, ok = q.Take() {
+		fmt.Printf("Dequeued item %d\n", item)
 	}
 
 	wg.Done()
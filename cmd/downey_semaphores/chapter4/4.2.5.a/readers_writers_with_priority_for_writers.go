@@ -4,8 +4,8 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/grsubramanian/go-playground/internal"
 	dsc "github.com/grsubramanian/go-playground/pkg/downey_semaphores/chapter4"
+	"github.com/grsubramanian/go-playground/pkg/semaphore"
 )
 
 var wg sync.WaitGroup
@@ -17,8 +17,8 @@ var c = 10
 var readPermitter = dsc.NewLightSwitch()
 var writePermitter = dsc.NewLightSwitch()
 
-var readPermission, _ = internal.NewSemaphore(1, 1)
-var writePermission, _ = internal.NewSemaphore(1, 1)
+var readPermission = semaphore.New(1)
+var writePermission = semaphore.New(1)
 
 func enterReadCriticalSection() {
 	readPermission.Wait()
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/grsubramanian/go-playground/internal"
+	"github.com/grsubramanian/go-playground/pkg/semaphore"
 )
 
 var wg sync.WaitGroup
@@ -14,9 +14,9 @@ var w = 10
 var c = 10
 
 var readers = 0
-var mutex, _ = internal.NewSemaphore(1, 1)
+var mutex = semaphore.New(1)
 
-var roomEmpty, _ = internal.NewSemaphore(1, 1)
+var roomEmpty = semaphore.New(1)
 
 func enterReadCriticalSection() {
 	mutex.Wait()
@@ -6,19 +6,19 @@ import (
 	"sync"
 	"time"
 
-	"github.com/grsubramanian/go-playground/internal"
+	"github.com/grsubramanian/go-playground/pkg/semaphore"
 )
 
 var wg sync.WaitGroup
 
 var n = 100
 
-var mutex, _ = internal.NewSemaphore(1, 1)
+var mutex = semaphore.New(1)
 
 var room1 = 0
-var room1Exit, _ = internal.NewSemaphore(1, 1)
+var room1Exit = semaphore.New(1)
 var room2 = 0
-var room2Exit, _ = internal.NewSemaphore(1, 0)
+var room2Exit = semaphore.New(0)
 
 func lockLockWithNoStarvation() {
 	// Entering room 1.
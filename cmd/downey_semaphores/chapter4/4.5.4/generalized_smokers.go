@@ -4,18 +4,18 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/grsubramanian/go-playground/internal"
+	"github.com/grsubramanian/go-playground/pkg/semaphore"
 )
 
 var wg sync.WaitGroup
 
 var s = 100
 
-var primaryMaterial, _ = internal.NewSemaphore(2*s, 0)
-var secondaryMaterial, _ = internal.NewSemaphore(2*s, 0)
-var tertiaryMaterial, _ = internal.NewSemaphore(2*s, 0)
+var primaryMaterial = semaphore.New(0)
+var secondaryMaterial = semaphore.New(0)
+var tertiaryMaterial = semaphore.New(0)
 
-func materialsProducer(material1 internal.Semaphore, material2 internal.Semaphore, name string) {
+func materialsProducer(material1 *semaphore.Semaphore, material2 *semaphore.Semaphore, name string) {
 	for i := 0; i < s; i++ {
 		fmt.Printf("%s materials producer producing...\n", name)
 		material1.Signal()
@@ -36,14 +36,14 @@ func nonTertiaryMaterialsProducer() {
 	materialsProducer(primaryMaterial, secondaryMaterial, "Non tertiary")
 }
 
-var mutex, _ = internal.NewSemaphore(1, 1)
+var mutex = semaphore.New(1)
 var numPrimaryMaterialInstancesStashed = 0
 var numSecondaryMaterialInstancesStashed = 0
 var numTertiaryMaterialInstancesStashed = 0
 
-var nonPrimaryMaterialsAvailable, _ = internal.NewSemaphore(2*s, 0)
-var nonSecondaryMaterialsAvailable, _ = internal.NewSemaphore(2*s, 0)
-var nonTertiaryMaterialsAvailable, _ = internal.NewSemaphore(2*s, 0)
+var nonPrimaryMaterialsAvailable = semaphore.New(0)
+var nonSecondaryMaterialsAvailable = semaphore.New(0)
+var nonTertiaryMaterialsAvailable = semaphore.New(0)
 
 func pusher(
 	material1Name string,
@@ -52,9 +52,9 @@ func pusher(
 	numMaterial1InstancesStashed *int,
 	numMaterial2InstancesStashed *int,
 	numMaterial3InstancesStashed *int,
-	material1 internal.Semaphore,
-	materials1And2Available internal.Semaphore,
-	materials1And3Available internal.Semaphore) {
+	material1 *semaphore.Semaphore,
+	materials1And2Available *semaphore.Semaphore,
+	materials1And3Available *semaphore.Semaphore) {
 
 	for true {
 		material1.Wait()
@@ -114,7 +114,7 @@ func pusherForConsumerConsumingTertiaryMaterial() {
 		nonPrimaryMaterialsAvailable)
 }
 
-func materialsConsumer(materialsAvailable internal.Semaphore, name string) {
+func materialsConsumer(materialsAvailable *semaphore.Semaphore, name string) {
 	for i := 0; i < s; i++ {
 		materialsAvailable.Wait()
 		fmt.Printf("%s materials consumer consuming...\n", name)
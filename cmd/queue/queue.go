@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/grsubramanian/go-playground/pkg/queue"
+)
+
+var (
+	capacity     int
+	numProducers int
+	numItems     int
+)
+
+func init() {
+	flag.IntVar(&capacity, "C", 4, "capacity of the queue")
+	flag.IntVar(&numProducers, "producers", 8, "number of concurrent producers")
+	flag.IntVar(&numItems, "N", 1000, "number of items each producer puts")
+	flag.Parse()
+}
+
+func main() {
+	checkNonBlocking()
+	checkContexts()
+	checkClose()
+	checkConcurrent()
+	log.Println("All checks passed")
+}
+
+func checkNonBlocking() {
+	q := queue.NewBlockingQueue[int](capacity)
+
+	if _, ok := q.TryTake(); ok {
+		log.Fatalf("validation failed: TryTake on an empty queue should fail")
+	}
+
+	for i := 0; i < capacity; i++ {
+		if !q.TryPut(i) {
+			log.Fatalf("validation failed: TryPut %d into a queue with capacity %d should succeed", i, capacity)
+		}
+	}
+	if q.TryPut(capacity) {
+		log.Fatalf("validation failed: TryPut into a full queue should fail")
+	}
+	if q.Len() != capacity || q.Cap() != capacity {
+		log.Fatalf("validation failed: Len is %d and Cap is %d, expected both to be %d", q.Len(), q.Cap(), capacity)
+	}
+
+	// Wrap around the ring buffer a few times.
+	for i := capacity; i < 3*capacity; i++ {
+		item, ok := q.TryTake()
+		if !ok || item != i-capacity {
+			log.Fatalf("validation failed: TryTake returned (%d, %v), expected (%d, true)", item, ok, i-capacity)
+		}
+		if !q.TryPut(i) {
+			log.Fatalf("validation failed: TryPut %d after a take should succeed", i)
+		}
+	}
+}
+
+func checkContexts() {
+	q := queue.NewBlockingQueue[int](1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := q.TakeContext(ctx); err != context.DeadlineExceeded {
+		log.Fatalf("validation failed: TakeContext on an empty queue returned %v, expected %v", err, context.DeadlineExceeded)
+	}
+
+	q.Put(1)
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.PutContext(ctx, 2); err != context.DeadlineExceeded {
+		log.Fatalf("validation failed: PutContext into a full queue returned %v, expected %v", err, context.DeadlineExceeded)
+	}
+
+	// A timed out put must not leave anything behind.
+	if q.Len() != 1 {
+		log.Fatalf("validation failed: Len is %d, expected 1", q.Len())
+	}
+}
+
+func checkClose() {
+	q := queue.NewBlockingQueue[int](capacity)
+
+	// Consumers blocked on an empty queue are woken up by Close.
+	done := make(chan bool)
+	go func() {
+		_, ok := q.Take()
+		done <- ok
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	q.Close()
+	if ok := <-done; ok {
+		log.Fatalf("validation failed: Take on a closed, empty queue should fail")
+	}
+
+	if err := q.Put(1); !errors.Is(err, queue.ErrClosed) {
+		log.Fatalf("validation failed: Put into a closed queue returned %v, expected %v", err, queue.ErrClosed)
+	}
+
+	// Items already in the queue can still be taken after Close.
+	q = queue.NewBlockingQueue[int](capacity)
+	q.Put(1)
+	q.Close()
+	if item, ok := q.Take(); !ok || item != 1 {
+		log.Fatalf("validation failed: Take after Close returned (%d, %v), expected (1, true)", item, ok)
+	}
+	if _, err := q.TakeContext(context.Background()); !errors.Is(err, queue.ErrClosed) {
+		log.Fatalf("validation failed: TakeContext on a drained queue returned %v, expected %v", err, queue.ErrClosed)
+	}
+}
+
+// Many producers put through a small queue at once. Every item must come out exactly once,
+// and the items of each producer must come out in the order they were put.
+func checkConcurrent() {
+	q := queue.NewBlockingQueue[[2]int](capacity)
+
+	var wg sync.WaitGroup
+	for p := 0; p < numProducers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < numItems; i++ {
+				q.Put([2]int{p, i})
+			}
+		}(p)
+	}
+	go func() {
+		wg.Wait()
+		q.Close()
+	}()
+
+	next := make([]int, numProducers)
+	for item, ok := q.Take(); ok; item, ok = q.Take() {
+		p, i := item[0], item[1]
+		if i != next[p] {
+			log.Fatalf("validation failed: took item %d of producer %d, expected item %d", i, p, next[p])
+		}
+		next[p]++
+	}
+
+	for p, n := range next {
+		if n != numItems {
+			log.Fatalf("validation failed: took %d items of producer %d, expected %d", n, p, numItems)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+
+	"github.com/grsubramanian/go-playground/pkg/redblacktree"
+	"github.com/pkg/errors"
+)
+
+// A single insert, delete or contains, as written in a script.
+type op struct {
+	name string
+	val  int
+}
+
+func (o op) String() string {
+	return fmt.Sprintf("%s %d", o.name, o.val)
+}
+
+func randomOps(r *rand.Rand, n int) []op {
+	names := []string{"insert", "insert", "delete", "contains"}
+
+	ops := make([]op, n)
+	for i := range ops {
+		ops[i] = op{name: names[r.Intn(len(names))], val: r.Intn(keySpan)}
+	}
+	return ops
+}
+
+// Applies the ops to a fresh tree, checking it after every op. Returns the index of the
+// first op after which the tree is broken, what kind of failure it is, and what's wrong
+// with the tree, or -1 if nothing is. Panics in the tree count as failures too.
+func failingOp(ops []op) (i int, kind string, err error) {
+
+	s := newSession(io.Discard)
+	s.t.SetTracer(nil)
+
+	defer func() {
+		if r := recover(); r != nil {
+			kind, err = "panic in "+ops[i].name, fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	for i = range ops {
+		err = s.apply(ops[i])
+		if err != nil {
+			return i, ops[i].name + " mismatch", err
+		}
+		err = checkTree(s.t)
+		if err != nil {
+			return i, checkKind(err), err
+		}
+	}
+	return -1, "", nil
+}
+
+// Returns the kind of a checkTree failure, which is the violated invariant, if any.
+func checkKind(err error) string {
+	var ie *redblacktree.InvariantError
+	if errors.As(err, &ie) {
+		return ie.Invariant.String() + " invariant"
+	}
+	return "height bound"
+}
+
+// Shrinks a failing sequence of ops, by repeatedly trying to drop chunks of it, halving the
+// chunk size each round. The result fails the same way as the original, and no longer does
+// if any single op is dropped. Candidates that fail some other way are rejected, so that
+// shrinking doesn't drift from the original failure to an unrelated one.
+func minimize(ops []op) []op {
+
+	// Everything after the failing op is irrelevant.
+	i, kind, _ := failingOp(ops)
+	ops = ops[:i+1]
+
+	for chunk := len(ops) / 2; chunk >= 1; chunk /= 2 {
+		for start := 0; start < len(ops); {
+			end := start + chunk
+			if end > len(ops) {
+				end = len(ops)
+			}
+
+			candidate := append(append([]op{}, ops[:start]...), ops[end:]...)
+			if i, k, err := failingOp(candidate); err != nil && k == kind {
+				ops = candidate[:i+1]
+			} else {
+				start = end
+			}
+		}
+	}
+	return ops
+}
+
+// Applies a random workload of -ops operations. On failure, bisects to a minimal failing
+// sequence, and writes it out as a script that reproduces the failure.
+func runOps() {
+
+	log.Printf("Seed is %d", seed)
+
+	r := rand.New(rand.NewSource(seed))
+	ops := randomOps(r, numOps)
+
+	i, kind, err := failingOp(ops)
+	if err == nil {
+		log.Printf("All %d ops passed", len(ops))
+		return
+	}
+	log.Printf("Op %d (%v) failed with %s: %v", i, ops[i], kind, err)
+
+	ops = minimize(ops)
+	_, _, err = failingOp(ops)
+	log.Printf("Minimized to %d ops, which fail with: %v", len(ops), err)
+
+	var script strings.Builder
+	for _, o := range ops {
+		fmt.Fprintln(&script, o)
+	}
+	fmt.Fprintln(&script, "check")
+	fmt.Print(script.String())
+
+	if err := os.WriteFile(failureFile, []byte(script.String()), 0644); err != nil {
+		log.Fatalln(errors.Wrapf(err, "writing failing sequence"))
+	}
+	log.Fatalf("Wrote failing sequence to %s. Reproduce with -script %s", failureFile, failureFile)
+}
@@ -2,8 +2,13 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
+	"math"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/grsubramanian/go-playground/pkg/redblacktree"
@@ -11,33 +16,276 @@ import (
 )
 
 var (
-	seed     int64
-	numItems int
+	seed      int64
+	numItems  int
+	format    string
+	framesDir string
+
+	repl       bool
+	scriptFile string
+
+	numOps      int
+	keySpan     int
+	failureFile string
 )
 
 func init() {
 	flag.Int64Var(&seed, "seed", time.Now().Unix(), "seed for repeatable testing")
 	flag.IntVar(&numItems, "N", 5, "number of items to insert into tree")
+	flag.StringVar(&format, "format", "indent", "format to print the tree in after every operation: indent, ascii or dot")
+	flag.StringVar(&framesDir, "frames", "", "if set, write the tree after every operation to a numbered file in this directory instead of printing it")
+	flag.BoolVar(&repl, "repl", false, "if set, read commands interactively from stdin")
+	flag.StringVar(&scriptFile, "script", "", "if set, run the commands in this file, stopping at the first failure")
+	flag.IntVar(&numOps, "ops", 0, "if set, apply this many random inserts, deletes and lookups, and bisect to a minimal failing sequence on failure")
+	flag.IntVar(&keySpan, "K", 64, "keys for -ops are drawn from [0, K)")
+	flag.StringVar(&failureFile, "failure", "redblacktree_failure.txt", "file to write the minimal failing sequence found by -ops to, as a script")
 	flag.Parse()
 }
 
+var numFrames = 0
+
+// Prints the tree in the chosen format, or writes it out as the next frame.
+func printTree(t *redblacktree.Tree[int]) {
+
+	if format == "indent" && framesDir != "" {
+		log.Fatalf("indent format can only be printed, not written to frames")
+	}
+
+	out := os.Stdout
+	if framesDir != "" {
+		var err error
+		out, err = os.Create(filepath.Join(framesDir, fmt.Sprintf("frame%04d.%s", numFrames, format)))
+		if err != nil {
+			log.Fatalln(errors.Wrapf(err, "creating frame"))
+		}
+		defer out.Close()
+	}
+	numFrames++
+
+	var err error
+	switch format {
+	case "indent":
+		t.Print()
+	case "ascii":
+		err = t.WriteASCII(out)
+	case "dot":
+		err = t.WriteDOT(out)
+	default:
+		log.Fatalf("unknown format %q", format)
+	}
+
+	if err != nil {
+		log.Fatalln(errors.Wrapf(err, "writing frame"))
+	}
+}
+
+// Logs every change the tree makes to its structure.
+type logTracer struct{}
+
+func (logTracer) OnInsert(obj int) {
+	log.Printf("Inserting : %v", obj)
+}
+
+func (logTracer) OnDelete(obj int) {
+	log.Printf("Deleting : %v", obj)
+}
+
+func (logTracer) OnRotate(obj int) {
+	log.Printf("Rotating : %v", obj)
+}
+
+func (logTracer) OnRecolor(obj int, c redblacktree.Color) {
+	log.Printf("Marking %v as %v", obj, c)
+}
+
+func check(t *redblacktree.Tree[int]) {
+	err := checkTree(t)
+	if err != nil {
+		log.Fatalln(errors.Wrapf(err, "validation failed"))
+	}
+}
+
+func checkTree(t *redblacktree.Tree[int]) error {
+	err := t.CheckInvariants()
+	if err != nil {
+		return err
+	}
+
+	// A red-black tree with n nodes is never taller than 2 * log2(n + 1).
+	stats := t.Stats()
+	bound := 2 * math.Log2(float64(stats.Len+1))
+	if float64(stats.Height) > bound {
+		return fmt.Errorf("height %d of tree with %d nodes exceeds %.2f", stats.Height, stats.Len, bound)
+	}
+	return nil
+}
+
 func main() {
 
+	switch {
+	case repl:
+		runREPL()
+	case scriptFile != "":
+		runScript(scriptFile)
+	case numOps > 0:
+		runOps()
+	default:
+		runPermutation()
+	}
+}
+
+// Inserts a random permutation of N values, interleaved with random deletes.
+func runPermutation() {
+
 	log.Printf("Seed is %d", seed)
 
 	r := rand.New(rand.NewSource(seed))
 	input := r.Perm(numItems)
 
 	t := &redblacktree.Tree[int]{}
+	t.SetTracer(logTracer{})
+	var present []int
 	for _, val := range input {
 		t.Insert(val)
+		present = append(present, val)
 
 		log.Printf("Tree after inserting %d", val)
-		t.Print()
+		printTree(t)
+		check(t)
 
-		err := t.CheckInvariants()
-		if err != nil {
-			log.Fatalln(errors.Wrapf(err, "validation failed"))
+		// Randomly interleave deletes with the inserts.
+		if r.Intn(2) == 0 {
+			i := r.Intn(len(present))
+			del := present[i]
+			present[i] = present[len(present)-1]
+			present = present[:len(present)-1]
+
+			if !t.Delete(del) {
+				log.Fatalf("validation failed: could not delete %d", del)
+			}
+			if t.Contains(del) {
+				log.Fatalf("validation failed: %d still present after delete", del)
+			}
+
+			log.Printf("Tree after deleting %d", del)
+			printTree(t)
+			check(t)
+		}
+	}
+
+	for _, val := range present {
+		if !t.Contains(val) {
+			log.Fatalf("validation failed: %d missing from tree", val)
+		}
+	}
+
+	sort.Ints(present)
+	checkIteration(t, present)
+	checkOrderStatistics(t, present)
+
+	stats := t.Stats()
+	log.Printf("Stats : len = %d, height = %d, black height = %d, rotations = %d, recolors = %d, rebalance steps = %d",
+		stats.Len, stats.Height, stats.BlackHeight, stats.Rotations, stats.Recolors, stats.RebalanceSteps)
+}
+
+func checkOrderStatistics(t *redblacktree.Tree[int], sorted []int) {
+
+	if t.Len() != len(sorted) {
+		log.Fatalf("validation failed: Len is %d, expected %d", t.Len(), len(sorted))
+	}
+
+	if len(sorted) > 0 {
+		if min, _ := t.Min(); min != sorted[0] {
+			log.Fatalf("validation failed: Min is %d, expected %d", min, sorted[0])
+		}
+		if max, _ := t.Max(); max != sorted[len(sorted)-1] {
+			log.Fatalf("validation failed: Max is %d, expected %d", max, sorted[len(sorted)-1])
+		}
+	}
+
+	for i, val := range sorted {
+		if got, ok := t.Select(i); !ok || got != val {
+			log.Fatalf("validation failed: Select(%d) is %d, expected %d", i, got, val)
+		}
+	}
+	if _, ok := t.Select(len(sorted)); ok {
+		log.Fatalf("validation failed: Select(%d) should be out of range", len(sorted))
+	}
+
+	for x := -1; x <= numItems; x++ {
+		// Position of the first value not smaller than x.
+		i := sort.SearchInts(sorted, x)
+
+		if got := t.Rank(x); got != i {
+			log.Fatalf("validation failed: Rank(%d) is %d, expected %d", x, got, i)
+		}
+
+		ceil, ok := t.Ceiling(x)
+		if ok != (i < len(sorted)) || (ok && ceil != sorted[i]) {
+			log.Fatalf("validation failed: Ceiling(%d) is (%d, %v)", x, ceil, ok)
+		}
+
+		// Position of the last value not larger than x.
+		j := sort.SearchInts(sorted, x+1) - 1
+		floor, ok := t.Floor(x)
+		if ok != (j >= 0) || (ok && floor != sorted[j]) {
+			log.Fatalf("validation failed: Floor(%d) is (%d, %v)", x, floor, ok)
+		}
+	}
+}
+
+func checkIteration(t *redblacktree.Tree[int], sorted []int) {
+
+	var ascending []int
+	t.Ascend(func(val int) bool {
+		ascending = append(ascending, val)
+		return true
+	})
+	checkEqual("Ascend", ascending, sorted)
+
+	var descending []int
+	t.Descend(func(val int) bool {
+		descending = append(descending, val)
+		return true
+	})
+	for i, j := 0, len(descending)-1; i < j; i, j = i+1, j-1 {
+		descending[i], descending[j] = descending[j], descending[i]
+	}
+	checkEqual("Descend", descending, sorted)
+
+	lo, hi := numItems/4, numItems/2
+	var inRange, expectedInRange []int
+	t.AscendRange(lo, hi, func(val int) bool {
+		inRange = append(inRange, val)
+		return true
+	})
+	for _, val := range sorted {
+		if val >= lo && val < hi {
+			expectedInRange = append(expectedInRange, val)
+		}
+	}
+	checkEqual("AscendRange", inRange, expectedInRange)
+
+	var atLeast, expectedAtLeast []int
+	t.AscendGreaterOrEqual(hi, func(val int) bool {
+		atLeast = append(atLeast, val)
+		return true
+	})
+	for _, val := range sorted {
+		if val >= hi {
+			expectedAtLeast = append(expectedAtLeast, val)
+		}
+	}
+	checkEqual("AscendGreaterOrEqual", atLeast, expectedAtLeast)
+}
+
+func checkEqual(name string, got []int, expected []int) {
+	if len(got) != len(expected) {
+		log.Fatalf("validation failed: %s yielded %v, expected %v", name, got, expected)
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			log.Fatalf("validation failed: %s yielded %v, expected %v", name, got, expected)
 		}
 	}
 }
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/grsubramanian/go-playground/pkg/redblacktree"
+	"github.com/pkg/errors"
+)
+
+// A session holds the tree that commands are run against, and a model of what it should contain.
+//
+// Commands, one per line:
+//
+//	insert <value>
+//	delete <value>
+//	contains <value>
+//	print
+//	check
+//	stats
+//	reset
+//
+// Blank lines and lines starting with # are ignored.
+type session struct {
+	t     *redblacktree.Tree[int]
+	model map[int]bool
+
+	out io.Writer
+}
+
+func newSession(out io.Writer) *session {
+	s := &session{out: out}
+	s.reset()
+	return s
+}
+
+func (s *session) reset() {
+	s.t = &redblacktree.Tree[int]{}
+	s.t.SetTracer(logTracer{})
+	s.model = map[int]bool{}
+}
+
+// Runs a single command. Returns an error if the command is malformed, or if the tree
+// disagrees with the model.
+func (s *session) exec(line string) error {
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+		return nil
+	}
+
+	cmd, args := fields[0], fields[1:]
+	switch cmd {
+	case "insert", "delete", "contains":
+		if len(args) != 1 {
+			return fmt.Errorf("%s takes exactly one value", cmd)
+		}
+		val, err := strconv.Atoi(args[0])
+		if err != nil {
+			return errors.Wrapf(err, "parsing value for %s", cmd)
+		}
+		return s.apply(op{name: cmd, val: val})
+	}
+
+	if len(args) != 0 {
+		return fmt.Errorf("%s takes no arguments", cmd)
+	}
+
+	switch cmd {
+	case "print":
+		printTree(s.t)
+	case "check":
+		err := checkTree(s.t)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(s.out, "ok")
+	case "stats":
+		stats := s.t.Stats()
+		fmt.Fprintf(s.out, "len = %d, height = %d, black height = %d, rotations = %d, recolors = %d, rebalance steps = %d\n",
+			stats.Len, stats.Height, stats.BlackHeight, stats.Rotations, stats.Recolors, stats.RebalanceSteps)
+	case "reset":
+		s.reset()
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+	return nil
+}
+
+func (s *session) apply(o op) error {
+
+	switch o.name {
+	case "insert":
+		s.t.Insert(o.val)
+		s.model[o.val] = true
+	case "delete":
+		deleted := s.t.Delete(o.val)
+		if deleted != s.model[o.val] {
+			return fmt.Errorf("delete %d returned %v, expected %v", o.val, deleted, s.model[o.val])
+		}
+		delete(s.model, o.val)
+	case "contains":
+		found := s.t.Contains(o.val)
+		if found != s.model[o.val] {
+			return fmt.Errorf("contains %d returned %v, expected %v", o.val, found, s.model[o.val])
+		}
+		fmt.Fprintln(s.out, found)
+	}
+	return nil
+}
+
+// Reads commands from stdin until EOF. Failures are reported, and don't end the session.
+func runREPL() {
+
+	s := newSession(os.Stdout)
+	in := bufio.NewScanner(os.Stdin)
+
+	fmt.Print("> ")
+	for in.Scan() {
+		err := s.exec(in.Text())
+		if err != nil {
+			fmt.Println("error:", err)
+		}
+		fmt.Print("> ")
+	}
+	fmt.Println()
+
+	if err := in.Err(); err != nil {
+		log.Fatalln(errors.Wrapf(err, "reading stdin"))
+	}
+}
+
+// Runs the commands in the file, and exits at the first failure.
+func runScript(path string) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalln(errors.Wrapf(err, "opening script"))
+	}
+	defer f.Close()
+
+	s := newSession(os.Stdout)
+	in := bufio.NewScanner(f)
+
+	for lineNum := 1; in.Scan(); lineNum++ {
+		err := s.exec(in.Text())
+		if err != nil {
+			log.Fatalln(errors.Wrapf(err, "%s:%d: %q failed", path, lineNum, in.Text()))
+		}
+	}
+
+	if err := in.Err(); err != nil {
+		log.Fatalln(errors.Wrapf(err, "reading script"))
+	}
+}
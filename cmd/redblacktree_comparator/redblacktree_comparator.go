@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/grsubramanian/go-playground/pkg/redblacktree"
+	"github.com/pkg/errors"
+)
+
+var (
+	seed     int64
+	numItems int
+)
+
+func init() {
+	flag.Int64Var(&seed, "seed", time.Now().Unix(), "seed for repeatable testing")
+	flag.IntVar(&numItems, "N", 10, "number of items to insert into each tree")
+	flag.Parse()
+}
+
+// A composite key, ordered by team and then by player.
+type entry struct {
+	team   string
+	player int
+}
+
+func compareEntries(a, b entry) int {
+	if a.team != b.team {
+		if a.team < b.team {
+			return -1
+		}
+		return 1
+	}
+	return a.player - b.player
+}
+
+func main() {
+
+	log.Printf("Seed is %d", seed)
+
+	r := rand.New(rand.NewSource(seed))
+
+	reversed := redblacktree.NewWithComparator(func(a, b int) int {
+		return b - a
+	})
+
+	entries := redblacktree.NewWithComparator(compareEntries)
+
+	teams := []string{"red", "green", "blue"}
+	for _, val := range r.Perm(numItems) {
+		reversed.Insert(val)
+
+		e := entry{team: teams[r.Intn(len(teams))], player: val}
+		entries.Insert(e)
+
+		for _, err := range []error{reversed.CheckInvariants(), entries.CheckInvariants()} {
+			if err != nil {
+				log.Fatalln(errors.Wrapf(err, "validation failed"))
+			}
+		}
+
+		if !reversed.Contains(val) || !entries.Contains(e) {
+			log.Fatalf("validation failed: %d missing from tree", val)
+		}
+	}
+
+	log.Printf("Reverse ordered tree")
+	reversed.Print()
+
+	log.Printf("Tree ordered by team and player")
+	entries.Print()
+}
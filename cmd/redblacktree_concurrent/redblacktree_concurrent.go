@@ -0,0 +1,133 @@
+// Stress test for redblacktree.ConcurrentTree. Run with -race, e.g.
+//
+//	go run -race ./cmd/redblacktree_concurrent -d 5s
+package main
+
+import (
+	"flag"
+	"log"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/grsubramanian/go-playground/pkg/redblacktree"
+	"github.com/pkg/errors"
+)
+
+var (
+	seed       int64
+	keySpan    int
+	numWriters int
+	numReaders int
+	duration   time.Duration
+)
+
+func init() {
+	flag.Int64Var(&seed, "seed", time.Now().Unix(), "seed for repeatable testing")
+	flag.IntVar(&keySpan, "K", 1000, "values are drawn from [0, K)")
+	flag.IntVar(&numWriters, "W", 4, "number of writer goroutines")
+	flag.IntVar(&numReaders, "R", 8, "number of reader goroutines")
+	flag.DurationVar(&duration, "d", 2*time.Second, "how long to run for")
+	flag.Parse()
+}
+
+var wg sync.WaitGroup
+
+var t = &redblacktree.ConcurrentTree[int]{}
+
+var stop atomic.Bool
+
+var numWrites, numReads, numSnapshots atomic.Int64
+
+func writer(r *rand.Rand) {
+	defer wg.Done()
+
+	for !stop.Load() {
+		val := r.Intn(keySpan)
+		if r.Intn(2) == 0 {
+			t.Insert(val)
+		} else {
+			t.Delete(val)
+		}
+		numWrites.Add(1)
+	}
+}
+
+func checkAscending(name string, scan func(fn func(val int) bool)) {
+	prev := -1
+	scan(func(val int) bool {
+		if val <= prev {
+			log.Fatalf("validation failed: %s yielded %d after %d", name, val, prev)
+		}
+		prev = val
+		return true
+	})
+}
+
+func reader(r *rand.Rand) {
+	defer wg.Done()
+
+	for !stop.Load() {
+		switch r.Intn(3) {
+		case 0:
+			t.Contains(r.Intn(keySpan))
+		case 1:
+			checkAscending("Ascend", t.Ascend)
+		case 2:
+			lo := r.Intn(keySpan)
+			checkAscending("AscendRange", func(fn func(val int) bool) {
+				t.AscendRange(lo, lo+keySpan/10, fn)
+			})
+		}
+		numReads.Add(1)
+	}
+}
+
+func snapshotter() {
+	defer wg.Done()
+
+	for !stop.Load() {
+		s := t.Snapshot()
+
+		// Keep scanning the snapshot while writers carry on. It should never change.
+		n := s.Len()
+		for i := 0; i < 10; i++ {
+			err := s.CheckInvariants()
+			if err != nil {
+				log.Fatalln(errors.Wrapf(err, "validation failed"))
+			}
+			checkAscending("Snapshot.Ascend", s.Ascend)
+			if s.Len() != n {
+				log.Fatalf("validation failed: snapshot changed size from %d to %d", n, s.Len())
+			}
+		}
+		numSnapshots.Add(1)
+	}
+}
+
+func main() {
+
+	r := rand.New(rand.NewSource(seed))
+
+	wg.Add(numWriters + numReaders + 1)
+	for i := 0; i < numWriters; i++ {
+		go writer(rand.New(rand.NewSource(r.Int63())))
+	}
+	for i := 0; i < numReaders; i++ {
+		go reader(rand.New(rand.NewSource(r.Int63())))
+	}
+	go snapshotter()
+
+	time.Sleep(duration)
+	stop.Store(true)
+	wg.Wait()
+
+	err := t.CheckInvariants()
+	if err != nil {
+		log.Fatalln(errors.Wrapf(err, "validation failed"))
+	}
+
+	log.Printf("Seed %d: %d writes, %d reads, %d snapshots, final size %d",
+		seed, numWrites.Load(), numReads.Load(), numSnapshots.Load(), t.Len())
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/grsubramanian/go-playground/pkg/redblacktree"
+	"github.com/pkg/errors"
+)
+
+var (
+	seed    int64
+	numOps  int
+	horizon int
+)
+
+func init() {
+	flag.Int64Var(&seed, "seed", time.Now().Unix(), "seed for repeatable testing")
+	flag.IntVar(&numOps, "N", 300, "number of inserts and deletes of scheduling windows")
+	flag.IntVar(&horizon, "H", 100, "windows start and end within [0, H)")
+	flag.Parse()
+}
+
+type window = redblacktree.Interval[int]
+
+// Returns the expected overlapping windows by brute force, in ascending order.
+func expectedOverlapping(model map[window]string, lo int, hi int) []redblacktree.IntervalEntry[int, string] {
+	var entries []redblacktree.IntervalEntry[int, string]
+	for w, v := range model {
+		if w.Lo <= hi && lo <= w.Hi {
+			entries = append(entries, redblacktree.IntervalEntry[int, string]{Interval: w, Value: v})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Lo != entries[j].Lo {
+			return entries[i].Lo < entries[j].Lo
+		}
+		return entries[i].Hi < entries[j].Hi
+	})
+	return entries
+}
+
+func checkEntries(name string, got []redblacktree.IntervalEntry[int, string], expected []redblacktree.IntervalEntry[int, string]) {
+	if len(got) != len(expected) {
+		log.Fatalf("validation failed: %s gave %v, expected %v", name, got, expected)
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			log.Fatalf("validation failed: %s gave %v, expected %v", name, got, expected)
+		}
+	}
+}
+
+func randomWindow(r *rand.Rand) (int, int) {
+	lo := r.Intn(horizon)
+	return lo, lo + r.Intn(horizon/5+1)
+}
+
+func main() {
+
+	log.Printf("Seed is %d", seed)
+
+	r := rand.New(rand.NewSource(seed))
+
+	it := &redblacktree.IntervalTree[int, string]{}
+	model := map[window]string{}
+
+	for i := 0; i < numOps; i++ {
+		lo, hi := randomWindow(r)
+		w := window{Lo: lo, Hi: hi}
+
+		if r.Intn(3) == 0 && len(model) > 0 {
+			// Delete an existing window most of the time.
+			for existing := range model {
+				w = existing
+				break
+			}
+			if !it.Delete(w.Lo, w.Hi) {
+				log.Fatalf("validation failed: could not delete %v", w)
+			}
+			delete(model, w)
+		} else {
+			v := fmt.Sprintf("job%d", i)
+			it.Insert(w.Lo, w.Hi, v)
+			model[w] = v
+		}
+
+		err := it.CheckInvariants()
+		if err != nil {
+			log.Fatalln(errors.Wrapf(err, "validation failed"))
+		}
+
+		if it.Len() != len(model) {
+			log.Fatalf("validation failed: tree has %d windows, expected %d", it.Len(), len(model))
+		}
+
+		qlo, qhi := randomWindow(r)
+		checkEntries(fmt.Sprintf("Overlapping(%d, %d)", qlo, qhi), it.Overlapping(qlo, qhi), expectedOverlapping(model, qlo, qhi))
+
+		point := r.Intn(horizon)
+		checkEntries(fmt.Sprintf("Stab(%d)", point), it.Stab(point), expectedOverlapping(model, point, point))
+	}
+
+	log.Printf("Windows at time %d: %v", horizon/2, it.Stab(horizon/2))
+}
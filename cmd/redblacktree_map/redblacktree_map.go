@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/grsubramanian/go-playground/pkg/redblacktree"
+	"github.com/pkg/errors"
+)
+
+var (
+	seed    int64
+	numOps  int
+	keySpan int
+)
+
+func init() {
+	flag.Int64Var(&seed, "seed", time.Now().Unix(), "seed for repeatable testing")
+	flag.IntVar(&numOps, "N", 100, "number of puts and deletes to apply to the map")
+	flag.IntVar(&keySpan, "K", 20, "keys are drawn from [0, K)")
+	flag.Parse()
+}
+
+func main() {
+
+	log.Printf("Seed is %d", seed)
+
+	r := rand.New(rand.NewSource(seed))
+
+	m := &redblacktree.Map[int, string]{}
+	expected := map[int]string{}
+
+	for i := 0; i < numOps; i++ {
+		key := r.Intn(keySpan)
+
+		if r.Intn(3) == 0 {
+			m.Delete(key)
+			delete(expected, key)
+		} else {
+			val := time.Duration(r.Int63()).String()
+			m.Put(key, val)
+			expected[key] = val
+		}
+
+		err := m.CheckInvariants()
+		if err != nil {
+			log.Fatalln(errors.Wrapf(err, "validation failed"))
+		}
+
+		if m.Len() != len(expected) {
+			log.Fatalf("validation failed: map has %d keys, expected %d", m.Len(), len(expected))
+		}
+
+		for k := 0; k < keySpan; k++ {
+			got, ok := m.Get(k)
+			want, wantOk := expected[k]
+			if ok != wantOk || got != want {
+				log.Fatalf("validation failed: Get(%d) = (%q, %v), expected (%q, %v)", k, got, ok, want, wantOk)
+			}
+		}
+	}
+
+	log.Printf("Map after %d operations", numOps)
+	m.Print()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/grsubramanian/go-playground/pkg/redblacktree"
+	"github.com/pkg/errors"
+)
+
+var (
+	seed       int64
+	numItems   int
+	windowSize int
+	valueSpan  int
+)
+
+func init() {
+	flag.Int64Var(&seed, "seed", time.Now().Unix(), "seed for repeatable testing")
+	flag.IntVar(&numItems, "N", 500, "number of items in the stream")
+	flag.IntVar(&windowSize, "W", 15, "size of the sliding window")
+	flag.IntVar(&valueSpan, "V", 10, "items are drawn from [0, V), so the window has lots of duplicates")
+	flag.Parse()
+}
+
+func check(ms *redblacktree.Multiset[int], window []int) {
+
+	err := ms.CheckInvariants()
+	if err != nil {
+		log.Fatalln(errors.Wrapf(err, "validation failed"))
+	}
+
+	sorted := append([]int{}, window...)
+	sort.Ints(sorted)
+
+	if ms.Len() != len(sorted) {
+		log.Fatalf("validation failed: multiset has %d items, expected %d", ms.Len(), len(sorted))
+	}
+
+	var got []int
+	ms.Ascend(func(val int) bool {
+		got = append(got, val)
+		return true
+	})
+	for i := range sorted {
+		if got[i] != sorted[i] {
+			log.Fatalf("validation failed: Ascend yielded %v, expected %v", got, sorted)
+		}
+		if val, _ := ms.Select(i); val != sorted[i] {
+			log.Fatalf("validation failed: Select(%d) is %d, expected %d", i, val, sorted[i])
+		}
+	}
+
+	for v := -1; v <= valueSpan; v++ {
+		lo := sort.SearchInts(sorted, v)
+		hi := sort.SearchInts(sorted, v+1)
+		if ms.Count(v) != hi-lo {
+			log.Fatalf("validation failed: Count(%d) is %d, expected %d", v, ms.Count(v), hi-lo)
+		}
+		if ms.Rank(v) != lo {
+			log.Fatalf("validation failed: Rank(%d) is %d, expected %d", v, ms.Rank(v), lo)
+		}
+	}
+}
+
+func main() {
+
+	log.Printf("Seed is %d", seed)
+
+	r := rand.New(rand.NewSource(seed))
+
+	ms := &redblacktree.Multiset[int]{}
+	var window []int
+
+	for i := 0; i < numItems; i++ {
+		val := r.Intn(valueSpan)
+		ms.Insert(val)
+		window = append(window, val)
+
+		if len(window) > windowSize {
+			if !ms.Remove(window[0]) {
+				log.Fatalf("validation failed: could not remove %d", window[0])
+			}
+			window = window[1:]
+		}
+
+		check(ms, window)
+
+		median, _ := ms.Select(ms.Len() / 2)
+		log.Printf("Median of window ending at item %d is %d", i, median)
+	}
+
+	for _, val := range window {
+		ms.RemoveAll(val)
+	}
+	check(ms, nil)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/grsubramanian/go-playground/pkg/redblacktree"
+	"github.com/pkg/errors"
+)
+
+var (
+	seed    int64
+	numOps  int
+	keySpan int
+)
+
+func init() {
+	flag.Int64Var(&seed, "seed", time.Now().Unix(), "seed for repeatable testing")
+	flag.IntVar(&numOps, "N", 200, "number of inserts and deletes, each producing a new version")
+	flag.IntVar(&keySpan, "K", 50, "values are drawn from [0, K)")
+	flag.Parse()
+}
+
+// A version of the tree, along with the values it's expected to hold.
+type version struct {
+	t        *redblacktree.PersistentTree[int]
+	expected []int
+}
+
+func check(v version) {
+	err := v.t.CheckInvariants()
+	if err != nil {
+		log.Fatalln(errors.Wrapf(err, "validation failed"))
+	}
+
+	var got []int
+	v.t.Ascend(func(val int) bool {
+		got = append(got, val)
+		return true
+	})
+
+	if len(got) != len(v.expected) || v.t.Len() != len(v.expected) {
+		log.Fatalf("validation failed: version holds %v, expected %v", got, v.expected)
+	}
+	for i := range got {
+		if got[i] != v.expected[i] {
+			log.Fatalf("validation failed: version holds %v, expected %v", got, v.expected)
+		}
+	}
+}
+
+func main() {
+
+	log.Printf("Seed is %d", seed)
+
+	r := rand.New(rand.NewSource(seed))
+
+	versions := []version{{t: &redblacktree.PersistentTree[int]{}}}
+
+	for i := 0; i < numOps; i++ {
+		prev := versions[len(versions)-1]
+		val := r.Intn(keySpan)
+
+		// Work out the values the next version should hold.
+		pos := sort.SearchInts(prev.expected, val)
+		exists := pos < len(prev.expected) && prev.expected[pos] == val
+		expected := append([]int{}, prev.expected...)
+
+		var next *redblacktree.PersistentTree[int]
+		if r.Intn(3) == 0 {
+			next = prev.t.Delete(val)
+			if exists {
+				expected = append(expected[:pos], expected[pos+1:]...)
+			}
+			log.Printf("Version %d deletes %d", len(versions), val)
+		} else {
+			next = prev.t.Insert(val)
+			if !exists {
+				expected = append(expected[:pos], append([]int{val}, expected[pos:]...)...)
+			}
+			log.Printf("Version %d inserts %d", len(versions), val)
+		}
+
+		versions = append(versions, version{t: next, expected: expected})
+
+		// Every version, old and new, should still hold exactly what it held when it was created.
+		for _, v := range versions {
+			check(v)
+		}
+	}
+
+	log.Printf("Final version")
+	versions[len(versions)-1].t.Print()
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/grsubramanian/go-playground/pkg/redblacktree"
+	"github.com/pkg/errors"
+)
+
+var (
+	seed      int64
+	numRounds int
+	maxItems  int
+)
+
+func init() {
+	flag.Int64Var(&seed, "seed", time.Now().Unix(), "seed for repeatable testing")
+	flag.IntVar(&numRounds, "rounds", 200, "number of rounds of random set operations")
+	flag.IntVar(&maxItems, "N", 100, "maximum number of items in each set")
+	flag.Parse()
+}
+
+var r *rand.Rand
+
+// Returns a random sorted set of distinct values.
+func randomSorted() []int {
+	n := r.Intn(maxItems + 1)
+	span := 2*n + 1
+	seen := map[int]bool{}
+	var vals []int
+	for len(vals) < n {
+		v := r.Intn(span)
+		if !seen[v] {
+			seen[v] = true
+			vals = append(vals, v)
+		}
+	}
+	sort.Ints(vals)
+	return vals
+}
+
+func contents(t *redblacktree.Tree[int]) []int {
+	vals := []int{}
+	t.Ascend(func(val int) bool {
+		vals = append(vals, val)
+		return true
+	})
+	return vals
+}
+
+func check(name string, t *redblacktree.Tree[int], expected []int) {
+
+	err := t.CheckInvariants()
+	if err != nil {
+		log.Fatalln(errors.Wrapf(err, "validation failed for %s", name))
+	}
+
+	got := contents(t)
+	if len(got) != len(expected) {
+		log.Fatalf("validation failed: %s holds %v, expected %v", name, got, expected)
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			log.Fatalf("validation failed: %s holds %v, expected %v", name, got, expected)
+		}
+	}
+}
+
+// Returns the sorted values satisfying the predicate, out of both inputs.
+func filter(a []int, b []int, keep func(inA bool, inB bool) bool) []int {
+	inA, inB := map[int]bool{}, map[int]bool{}
+	for _, v := range a {
+		inA[v] = true
+	}
+	for _, v := range b {
+		inB[v] = true
+	}
+
+	vals := []int{}
+	for _, v := range append(append([]int{}, a...), b...) {
+		if keep(inA[v], inB[v]) {
+			vals = append(vals, v)
+			delete(inA, v)
+			delete(inB, v)
+		}
+	}
+	sort.Ints(vals)
+	return vals
+}
+
+func main() {
+
+	log.Printf("Seed is %d", seed)
+
+	r = rand.New(rand.NewSource(seed))
+
+	for i := 0; i < numRounds; i++ {
+		a, b := randomSorted(), randomSorted()
+
+		ta := redblacktree.FromSorted(a)
+		check("FromSorted", ta, a)
+		tb := redblacktree.FromSorted(b)
+		check("FromSorted", tb, b)
+
+		u := ta.Clone()
+		u.Union(tb.Clone())
+		check("Union", u, filter(a, b, func(inA, inB bool) bool { return inA || inB }))
+
+		in := ta.Clone()
+		in.Intersection(tb.Clone())
+		check("Intersection", in, filter(a, b, func(inA, inB bool) bool { return inA && inB }))
+
+		d := ta.Clone()
+		d.Difference(tb.Clone())
+		check("Difference", d, filter(a, b, func(inA, inB bool) bool { return inA && !inB }))
+
+		// The originals are untouched by operations on their clones.
+		check("original", ta, a)
+		check("original", tb, b)
+
+		pivot := r.Intn(2*maxItems + 1)
+		i := sort.SearchInts(a, pivot)
+		found := i < len(a) && a[i] == pivot
+		j := i
+		if found {
+			j++
+		}
+
+		l, ok, rt := ta.Split(pivot)
+		if ok != found {
+			log.Fatalf("validation failed: Split(%d) reported found = %v", pivot, ok)
+		}
+		check("Split", l, a[:i])
+		check("Split", rt, a[j:])
+		check("split tree", ta, []int{})
+
+		l.Join(rt)
+		check("Join", l, append(append([]int{}, a[:i]...), a[j:]...))
+	}
+
+	log.Printf("Completed %d rounds", numRounds)
+}
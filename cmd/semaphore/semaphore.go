@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/grsubramanian/go-playground/pkg/semaphore"
+)
+
+var (
+	seed       int64
+	numWorkers int
+	maxWeight  int
+	numIters   int
+)
+
+func init() {
+	flag.Int64Var(&seed, "seed", time.Now().Unix(), "seed for repeatable testing")
+	flag.IntVar(&numWorkers, "workers", 16, "number of goroutines acquiring and releasing concurrently")
+	flag.IntVar(&maxWeight, "W", 4, "workers acquire between 1 and W units at a time")
+	flag.IntVar(&numIters, "iters", 1000, "number of acquires per worker")
+	flag.Parse()
+}
+
+func main() {
+
+	log.Printf("Seed is %d", seed)
+
+	checkNonBlocking()
+	checkTimeouts()
+	checkFIFO()
+	checkNoStarvation()
+	checkWeighted()
+	log.Println("All checks passed")
+}
+
+func checkNonBlocking() {
+	s := semaphore.New(1)
+
+	if !s.TryWait() {
+		log.Fatalf("validation failed: TryWait on a semaphore with value 1 should succeed")
+	}
+	if s.TryWait() {
+		log.Fatalf("validation failed: TryWait on a semaphore with value 0 should fail")
+	}
+
+	// Signals are never bounded, and never block.
+	s.SignalN(1000)
+	if s.Value() != 1000 {
+		log.Fatalf("validation failed: value is %d after 1000 signals, expected 1000", s.Value())
+	}
+}
+
+func checkTimeouts() {
+	s := semaphore.New(0)
+
+	start := time.Now()
+	if s.WaitTimeout(20 * time.Millisecond) {
+		log.Fatalf("validation failed: WaitTimeout on a semaphore with value 0 should time out")
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		log.Fatalf("validation failed: WaitTimeout returned after %v, expected at least 20ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if err := s.WaitContext(ctx); err != context.Canceled {
+		log.Fatalf("validation failed: WaitContext returned %v, expected %v", err, context.Canceled)
+	}
+
+	// A cancelled waiter must leave the semaphore as it found it.
+	s.Signal()
+	if !s.WaitTimeout(time.Second) {
+		log.Fatalf("validation failed: WaitTimeout should succeed after a signal")
+	}
+	if s.Value() != 0 {
+		log.Fatalf("validation failed: value is %d, expected 0", s.Value())
+	}
+}
+
+// Queues up waiters one at a time, and checks that they're woken up in the same order.
+func checkFIFO() {
+	s := semaphore.New(0)
+
+	const n = 20
+	order := make(chan int, n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			s.Wait()
+			order <- i
+		}(i)
+
+		// Wait until the goroutine has queued up.
+		for s.Waiters() != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		s.Signal()
+		if got := <-order; got != i {
+			log.Fatalf("validation failed: waiter %d woke up, expected waiter %d", got, i)
+		}
+	}
+}
+
+// A large acquire at the front of the queue must hold back smaller ones behind it.
+func checkNoStarvation() {
+	s := semaphore.New(0)
+
+	large := make(chan struct{})
+	go func() {
+		s.Acquire(3)
+		close(large)
+	}()
+	for s.Waiters() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	small := make(chan struct{})
+	go func() {
+		s.Acquire(1)
+		close(small)
+	}()
+	for s.Waiters() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	s.Release(1)
+	select {
+	case <-small:
+		log.Fatalf("validation failed: small acquire jumped ahead of the large one")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	s.Release(2)
+	<-large
+
+	s.Release(1)
+	<-small
+}
+
+// Many workers acquire random weights at once. The units held at any time must never exceed
+// the capacity of the semaphore, and at the end all units must be back.
+func checkWeighted() {
+	const capacity = 8
+	if maxWeight > capacity {
+		log.Fatalf("-W should be at most %d", capacity)
+	}
+
+	s := semaphore.New(capacity)
+
+	var mu sync.Mutex
+	held := 0
+
+	var wg sync.WaitGroup
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func(r *rand.Rand) {
+			defer wg.Done()
+
+			for i := 0; i < numIters; i++ {
+				n := 1 + r.Intn(maxWeight)
+				s.Acquire(n)
+
+				mu.Lock()
+				held += n
+				if held > capacity {
+					log.Fatalf("validation failed: %d units held, capacity is %d", held, capacity)
+				}
+				mu.Unlock()
+
+				mu.Lock()
+				held -= n
+				mu.Unlock()
+				s.Release(n)
+			}
+		}(rand.New(rand.NewSource(seed + int64(w))))
+	}
+	wg.Wait()
+
+	if s.Value() != capacity {
+		log.Fatalf("validation failed: value is %d after all releases, expected %d", s.Value(), capacity)
+	}
+}
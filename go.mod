@@ -6,3 +6,5 @@ require (
 	github.com/pkg/errors v0.9.1
 	golang.org/x/exp v0.0.0-20230626212559-97b1e661b5df
 )
+
+require github.com/google/btree v1.1.3
@@ -0,0 +1,190 @@
+// Package avl implements an AVL tree, in which the heights of the two subtrees of any node
+// differ by at most one. It's more rigidly balanced than a red-black tree, so lookups are
+// faster, at the cost of more rotations on insert.
+package avl
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+type node[T constraints.Ordered] struct {
+	children [2]*node[T]
+
+	// Number of nodes on the longest path from this node down to a leaf.
+	height int
+
+	data T
+}
+
+func (nd *node[T]) getHeight() int {
+	if nd == nil {
+		return 0
+	}
+	return nd.height
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func (nd *node[T]) updateHeight() {
+	nd.height = 1 + maxInt(nd.children[0].getHeight(), nd.children[1].getHeight())
+}
+
+// Returns how much taller the right subtree is than the left subtree.
+func (nd *node[T]) balanceFactor() int {
+	return nd.children[1].getHeight() - nd.children[0].getHeight()
+}
+
+// Tree is an ordered set of values. The zero value is an empty tree.
+type Tree[T constraints.Ordered] struct {
+	root *node[T]
+
+	size      int
+	rotations int
+}
+
+func (t *Tree[T]) Insert(obj T) {
+	t.root = t.insert(t.root, obj)
+}
+
+func (t *Tree[T]) insert(nd *node[T], obj T) *node[T] {
+
+	if nd == nil {
+		t.size++
+		return &node[T]{height: 1, data: obj}
+	}
+
+	if obj < nd.data {
+		nd.children[0] = t.insert(nd.children[0], obj)
+	} else if obj > nd.data {
+		nd.children[1] = t.insert(nd.children[1], obj)
+	} else {
+		// No op. Object already exists.
+		return nd
+	}
+
+	nd.updateHeight()
+	return t.rebalance(nd)
+}
+
+// Restores the balance of the node, whose subtrees' heights differ by at most two.
+// Returns the new root of the subtree.
+func (t *Tree[T]) rebalance(nd *node[T]) *node[T] {
+
+	bf := nd.balanceFactor()
+	if bf >= -1 && bf <= 1 {
+		return nd
+	}
+
+	// The taller side.
+	dir := 0
+	if bf > 1 {
+		dir = 1
+	}
+
+	// If the taller child leans the other way, the child, its child and the node form a triangle.
+	// Rotate the child's child up so that they get into a straight line.
+	c := nd.children[dir]
+	if (dir == 1 && c.balanceFactor() < 0) || (dir == 0 && c.balanceFactor() > 0) {
+		nd.children[dir] = t.rotate(c, dir)
+	}
+
+	return t.rotate(nd, 1-dir)
+}
+
+// Rotates the child of nd on the side opposite to dir up, in the direction dir.
+// i.e. dir = 0 is a left rotation, and dir = 1 is a right rotation.
+// Returns the new root of the subtree.
+func (t *Tree[T]) rotate(nd *node[T], dir int) *node[T] {
+	t.rotations++
+
+	x := nd.children[1-dir]
+	nd.children[1-dir] = x.children[dir]
+	x.children[dir] = nd
+
+	// nd is now a child of x, so update its height first.
+	nd.updateHeight()
+	x.updateHeight()
+	return x
+}
+
+func (t *Tree[T]) Contains(obj T) bool {
+	nd := t.root
+	for nd != nil {
+		if obj < nd.data {
+			nd = nd.children[0]
+		} else if obj > nd.data {
+			nd = nd.children[1]
+		} else {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Tree[T]) Len() int {
+	return t.size
+}
+
+// Returns the number of nodes on the longest path from the root down to a leaf.
+func (t *Tree[T]) Height() int {
+	return t.root.getHeight()
+}
+
+// Returns the number of rotations performed since the tree was created.
+func (t *Tree[T]) Rotations() int {
+	return t.rotations
+}
+
+// Validates sort order, recorded heights and balance. Returns the number of nodes in the subtree.
+func (nd *node[T]) validate(lo *T, hi *T) (int, error) {
+
+	if nd == nil {
+		return 0, nil
+	}
+
+	if (lo != nil && nd.data <= *lo) || (hi != nil && nd.data >= *hi) {
+		return 0, fmt.Errorf("node %v is out of order", nd.data)
+	}
+
+	nl, err := nd.children[0].validate(lo, &nd.data)
+	if err != nil {
+		return 0, err
+	}
+
+	nr, err := nd.children[1].validate(&nd.data, hi)
+	if err != nil {
+		return 0, err
+	}
+
+	expected := 1 + maxInt(nd.children[0].getHeight(), nd.children[1].getHeight())
+	if nd.height != expected {
+		return 0, fmt.Errorf("node %v has height %d, but its subtree has height %d", nd.data, nd.height, expected)
+	}
+
+	if bf := nd.balanceFactor(); bf < -1 || bf > 1 {
+		return 0, fmt.Errorf("node %v has a balance factor of %d", nd.data, bf)
+	}
+
+	return nl + nr + 1, nil
+}
+
+func (t *Tree[T]) CheckInvariants() error {
+
+	n, err := t.root.validate(nil, nil)
+	if err != nil {
+		return err
+	}
+
+	if n != t.size {
+		return fmt.Errorf("tree has %d nodes, but records a size of %d", n, t.size)
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+package avl
+
+import (
+	"testing"
+
+	"github.com/grsubramanian/go-playground/pkg/orderedset"
+	"github.com/grsubramanian/go-playground/pkg/orderedset/orderedsettest"
+)
+
+var (
+	_ orderedset.OrderedSet[int] = &Tree[int]{}
+	_ orderedset.Balanced        = &Tree[int]{}
+)
+
+func TestOrderedSetConformance(t *testing.T) {
+	orderedsettest.Run(t, func() orderedset.OrderedSet[int] { return &Tree[int]{} }, func(i int) int { return i }, 1000)
+}
@@ -0,0 +1,309 @@
+package chapter4
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+/**
+ * Records why a stage stopped. The error is nil if the stage ran to completion, or ctx.Err()
+ * if it was cancelled first. Stages hand out its Err method, which is only meaningful once
+ * the stage's output channel is closed.
+ */
+type stopReason struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (s *stopReason) cancelled(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err == nil {
+		s.err = ctx.Err()
+	}
+}
+
+/**
+ * Records that the stage's input closed. If ctx is done by then, the input most likely closed
+ * because an upstream stage was cancelled through the same ctx, so the stage was cancelled too.
+ */
+func (s *stopReason) inputClosed(ctx context.Context) {
+	if ctx.Err() != nil {
+		s.cancelled(ctx)
+	}
+}
+
+func (s *stopReason) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+/**
+ * Like Take, but cancelled through ctx.
+ */
+func TakeContext[T any](ctx context.Context, in <-chan T, n int) (<-chan T, func() error) {
+
+	out := make(chan T)
+	var stop stopReason
+	go func() {
+		defer close(out)
+		for i := 0; i < n; i++ {
+			var v T
+			select {
+			case <-ctx.Done():
+				stop.cancelled(ctx)
+				return
+			case val, ok := <-in:
+				if !ok {
+					stop.inputClosed(ctx)
+					return
+				}
+				v = val
+			}
+
+			select {
+			case <-ctx.Done():
+				stop.cancelled(ctx)
+				return
+			case out <- v:
+			}
+		}
+	}()
+	return out, stop.Err
+}
+
+/**
+ * Like Repeat, but cancelled through ctx. Since the stream is endless, it only ever stops
+ * with ctx.Err(), unless there are no input values.
+ */
+func RepeatContext[T any](ctx context.Context, vals ...T) (<-chan T, func() error) {
+
+	out := make(chan T)
+	var stop stopReason
+	go func() {
+		defer close(out)
+		if len(vals) == 0 {
+			return
+		}
+		for {
+			for _, v := range vals {
+				select {
+				case <-ctx.Done():
+					stop.cancelled(ctx)
+					return
+				case out <- v:
+				}
+			}
+		}
+	}()
+	return out, stop.Err
+}
+
+/**
+ * Like OrDone, but cancelled through ctx.
+ */
+func OrDoneContext[T any](ctx context.Context, c <-chan T) (<-chan T, func() error) {
+
+	out := make(chan T)
+	var stop stopReason
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				stop.cancelled(ctx)
+				return
+			case v, ok := <-c:
+				if !ok {
+					stop.inputClosed(ctx)
+					return
+				}
+				select {
+				case <-ctx.Done():
+					stop.cancelled(ctx)
+					return
+				case out <- v:
+				}
+			}
+		}
+	}()
+	return out, stop.Err
+}
+
+/**
+ * Like FanIn, but cancelled through ctx. Unlike FanIn, an input channel that never closes
+ * doesn't keep its goroutine alive once ctx is done.
+ */
+func FanInContext[T any](ctx context.Context, channels ...<-chan T) (<-chan T, func() error) {
+
+	out := make(chan T)
+	var stop stopReason
+
+	var wg sync.WaitGroup
+	wg.Add(len(channels))
+
+	multiplex := func(c <-chan T) {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				stop.cancelled(ctx)
+				return
+			case v, ok := <-c:
+				if !ok {
+					stop.inputClosed(ctx)
+					return
+				}
+				select {
+				case <-ctx.Done():
+					stop.cancelled(ctx)
+					return
+				case out <- v:
+				}
+			}
+		}
+	}
+
+	for _, c := range channels {
+		go multiplex(c)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out, stop.Err
+}
+
+/**
+ * Like Tee, but cancelled through ctx.
+ */
+func TeeContext[T any](ctx context.Context, c <-chan T) (<-chan T, <-chan T, func() error) {
+
+	out1 := make(chan T)
+	out2 := make(chan T)
+	var stop stopReason
+
+	go func() {
+		defer close(out1)
+		defer close(out2)
+
+		for {
+			var v T
+			select {
+			case <-ctx.Done():
+				stop.cancelled(ctx)
+				return
+			case val, ok := <-c:
+				if !ok {
+					stop.inputClosed(ctx)
+					return
+				}
+				v = val
+			}
+
+			var out1Cpy, out2Cpy = out1, out2
+			for i := 0; i < 2; i++ {
+				select {
+				case <-ctx.Done():
+					stop.cancelled(ctx)
+					return
+				case out1Cpy <- v:
+					out1Cpy = nil
+				case out2Cpy <- v:
+					out2Cpy = nil
+				}
+			}
+		}
+	}()
+
+	return out1, out2, stop.Err
+}
+
+/**
+ * Like Bridge, but cancelled through ctx.
+ */
+func BridgeContext[T any](ctx context.Context, chanStream <-chan <-chan T) (<-chan T, func() error) {
+
+	out := make(chan T)
+	var stop stopReason
+
+	go func() {
+		defer close(out)
+
+		for {
+			var valStream <-chan T
+			select {
+			case <-ctx.Done():
+				stop.cancelled(ctx)
+				return
+			case v, ok := <-chanStream:
+				if !ok {
+					stop.inputClosed(ctx)
+					return
+				}
+				valStream = v
+			}
+
+			for drained := false; !drained; {
+				select {
+				case <-ctx.Done():
+					stop.cancelled(ctx)
+					return
+				case v, ok := <-valStream:
+					if !ok {
+						drained = true
+						break
+					}
+					select {
+					case <-ctx.Done():
+						stop.cancelled(ctx)
+						return
+					case out <- v:
+					}
+				}
+			}
+		}
+	}()
+
+	return out, stop.Err
+}
+
+/**
+ * Like Or, but also closes when ctx is done, in which case the error is ctx.Err(). Uses a single
+ * goroutine however many channels there are, and that goroutine is gone once ctx is done.
+ * Like Or, returns a nil channel if there's nothing that could ever close it.
+ */
+func OrContext(ctx context.Context, channels ...<-chan interface{}) (<-chan interface{}, func() error) {
+
+	var stop stopReason
+
+	cases := make([]reflect.SelectCase, 0, len(channels)+1)
+	for _, c := range channels {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c)})
+	}
+
+	// A context that can't be cancelled has a nil Done channel, which would never fire.
+	if done := ctx.Done(); done != nil {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(done)})
+	}
+
+	if len(cases) == 0 {
+		return nil, stop.Err
+	}
+
+	orDone := make(chan interface{})
+	go func() {
+		defer close(orDone)
+
+		// Whether it was ctx that fired, or a channel closed by an upstream stage
+		// that was cancelled through ctx, the error is the same.
+		reflect.Select(cases)
+		stop.inputClosed(ctx)
+	}()
+	return orDone, stop.Err
+}
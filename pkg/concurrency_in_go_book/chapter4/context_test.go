@@ -0,0 +1,163 @@
+package chapter4
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// Builds pipelines out of every stage, reads a little from each, and then cancels.
+// Every stage must stop with context.Canceled, and leave no goroutines behind.
+func TestContextStagesCancelled(t *testing.T) {
+
+	baseline := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ones, repeatErr := RepeatContext(ctx, 1)
+	twos, _ := RepeatContext(ctx, 2)
+	fannedIn, fanInErr := FanInContext(ctx, ones, twos)
+	taken, takeErr := TakeContext(ctx, fannedIn, 1000)
+	left, right, teeErr := TeeContext(ctx, taken)
+	orDone, orDoneErr := OrDoneContext(ctx, left)
+
+	// A stream of streams that never ends.
+	chanStream := make(chan (<-chan int))
+	go func() {
+		defer close(chanStream)
+		for {
+			s, _ := RepeatContext(ctx, 3)
+			select {
+			case <-ctx.Done():
+				return
+			case chanStream <- s:
+			}
+		}
+	}()
+	bridged, bridgeErr := BridgeContext(ctx, chanStream)
+
+	// Channels that never fire.
+	never := make(chan interface{})
+	or, orErr := OrContext(ctx, never, never, never, never, never)
+
+	for i := 0; i < 10; i++ {
+		<-orDone
+		<-right
+		<-bridged
+	}
+
+	cancel()
+
+	for _, c := range []<-chan int{ones, twos, fannedIn, taken, left, right, orDone, bridged} {
+		drain(c)
+	}
+	<-or
+
+	errs := map[string]func() error{
+		"RepeatContext": repeatErr,
+		"FanInContext":  fanInErr,
+		"TakeContext":   takeErr,
+		"TeeContext":    teeErr,
+		"OrDoneContext": orDoneErr,
+		"BridgeContext": bridgeErr,
+		"OrContext":     orErr,
+	}
+	for name, err := range errs {
+		if err() != context.Canceled {
+			t.Errorf("%s stopped with %v, expected %v", name, err(), context.Canceled)
+		}
+	}
+
+	checkGoroutines(t, baseline)
+}
+
+// Stages that run to completion must stop with no error.
+func TestContextStagesCompleted(t *testing.T) {
+
+	baseline := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ones, _ := RepeatContext(ctx, 1)
+	taken, takeErr := TakeContext(ctx, ones, 5)
+	orDone, orDoneErr := OrDoneContext(ctx, taken)
+
+	sum := 0
+	for v := range orDone {
+		sum += v
+	}
+	if sum != 5 {
+		t.Errorf("sum is %d, expected 5", sum)
+	}
+	if takeErr() != nil || orDoneErr() != nil {
+		t.Errorf("completed stages stopped with %v and %v, expected no error", takeErr(), orDoneErr())
+	}
+
+	fired := make(chan interface{})
+	close(fired)
+	or, orErr := OrContext(ctx, make(chan interface{}), fired)
+	<-or
+	if orErr() != nil {
+		t.Errorf("OrContext stopped with %v, expected no error", orErr())
+	}
+
+	// The RepeatContext stage above only stops on cancel.
+	cancel()
+	drain(ones)
+	checkGoroutines(t, baseline)
+}
+
+// The error is whatever the context reports, here its deadline.
+func TestContextStagesDeadline(t *testing.T) {
+
+	baseline := runtime.NumGoroutine()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	never := make(chan int)
+	orDone, orDoneErr := OrDoneContext(ctx, never)
+	drain(orDone)
+
+	if orDoneErr() != context.DeadlineExceeded {
+		t.Errorf("OrDoneContext stopped with %v, expected %v", orDoneErr(), context.DeadlineExceeded)
+	}
+
+	checkGoroutines(t, baseline)
+}
+
+// With nothing that could ever close it, OrContext doesn't start a goroutine that would wait forever.
+func TestOrContextNothingToWaitFor(t *testing.T) {
+
+	baseline := runtime.NumGoroutine()
+
+	or, orErr := OrContext(context.Background())
+	if or != nil {
+		t.Errorf("OrContext with no channels and no cancellation returned a non-nil channel")
+	}
+	if orErr() != nil {
+		t.Errorf("OrContext stopped with %v, expected no error", orErr())
+	}
+
+	checkGoroutines(t, baseline)
+}
+
+func drain[T any](c <-chan T) {
+	for range c {
+	}
+}
+
+// Waits for the number of goroutines to come back down to the baseline, failing if it doesn't
+// within a second. Goroutines take a little while to exit after their channels close.
+func checkGoroutines(t *testing.T, baseline int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			n := runtime.Stack(buf, true)
+			t.Fatalf("%d goroutines running, expected %d\n%s", runtime.NumGoroutine(), baseline, buf[:n])
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -1,18 +1,18 @@
 package chapter4
 
 /**
- * A pre-emptible read-only channel that streams the input integers in order.
+ * A pre-emptible read-only channel that streams the input values in order.
  */
-var IntStream = func(done <-chan interface{}, vals ...int) <-chan int {
+func Generate[T any](done <-chan interface{}, vals ...T) <-chan T {
 
-	out := make(chan int)
+	out := make(chan T)
 	go func() {
 		defer close(out)
-		for _, i := range vals {
+		for _, v := range vals {
 			select {
 			case <-done:
 				return
-			case out<- i:
+			case out <- v:
 			}
 		}
 	}()
@@ -20,18 +20,48 @@ var IntStream = func(done <-chan interface{}, vals ...int) <-chan int {
 }
 
 /**
- * A pre-emptible read-only channel that streams the input integer repeatedly forever.
+ * A pre-emptible read-only channel that streams the input integers in order.
+ */
+var IntStream = Generate[int]
+
+/**
+ * A pre-emptible read-only channel that streams the input values in order, repeatedly forever.
+ * Closes straight away if there are no input values.
  */
-var Repeat = func(done <-chan interface{}, val int) <-chan int {
+func Repeat[T any](done <-chan interface{}, vals ...T) <-chan T {
 
-	out := make(chan int)
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		if len(vals) == 0 {
+			return
+		}
+		for {
+			for _, v := range vals {
+				select {
+				case <-done:
+					return
+				case out <- v:
+				}
+			}
+		}
+	}()
+	return out
+}
+
+/**
+ * A pre-emptible read-only channel that streams the results of calling fn repeatedly forever.
+ */
+func RepeatFn[T any](done <-chan interface{}, fn func() T) <-chan T {
+
+	out := make(chan T)
 	go func() {
 		defer close(out)
 		for {
 			select {
 			case <-done:
 				return
-			case out<- val:
+			case out <- fn():
 			}
 		}
 	}()
@@ -39,18 +69,30 @@ var Repeat = func(done <-chan interface{}, val int) <-chan int {
 }
 
 /**
- * A pr-emptible read-only channel that streams only the first 'n' integers from the input stream.
+ * A pre-emptible read-only channel that streams only the first 'n' values from the input stream.
+ * Closes early if the input stream closes first.
  */
-var Take = func(done <-chan interface{}, in <-chan int, n int) <-chan int {
+func Take[T any](done <-chan interface{}, in <-chan T, n int) <-chan T {
 
-	out := make(chan int)
+	out := make(chan T)
 	go func() {
 		defer close(out)
 		for i := 0; i < n; i++ {
+			var v T
+			select {
+			case <-done:
+				return
+			case val, ok := <-in:
+				if !ok {
+					return
+				}
+				v = val
+			}
+
 			select {
 			case <-done:
 				return
-			case out<- <-in:
+			case out <- v:
 			}
 		}
 	}()
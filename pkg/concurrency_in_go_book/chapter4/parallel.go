@@ -0,0 +1,151 @@
+package chapter4
+
+import (
+	"context"
+	"sync"
+)
+
+/**
+ * Applies fn to each value from the input stream on 'workers' goroutines, and streams the results
+ * in the same order as the input values. A slow value holds back the results behind it, and at
+ * most 'workers' of those are buffered for reordering, after which the input stops being read
+ * until the slow value is done. Cancelled through ctx. Panics if workers is not positive.
+ */
+func ParallelMap[T any, U any](ctx context.Context, in <-chan T, workers int, fn func(T) U) (<-chan U, func() error) {
+
+	if workers <= 0 {
+		panic("number of workers should be positive")
+	}
+
+	type job struct {
+		v T
+
+		// Buffered, so that the worker never blocks on handing over the result.
+		result chan U
+	}
+
+	jobs := make(chan job)
+
+	// Results still to be streamed, in input order. This is the reorder buffer.
+	pending := make(chan chan U, workers)
+
+	out := make(chan U)
+	var stop stopReason
+
+	// Dispatcher.
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+
+		for {
+			var v T
+			select {
+			case <-ctx.Done():
+				stop.cancelled(ctx)
+				return
+			case val, ok := <-in:
+				if !ok {
+					stop.inputClosed(ctx)
+					return
+				}
+				v = val
+			}
+
+			j := job{v: v, result: make(chan U, 1)}
+			select {
+			case <-ctx.Done():
+				stop.cancelled(ctx)
+				return
+			case jobs <- j:
+			}
+
+			select {
+			case <-ctx.Done():
+				stop.cancelled(ctx)
+				return
+			case pending <- j.result:
+			}
+		}
+	}()
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for j := range jobs {
+				j.result <- fn(j.v)
+			}
+		}()
+	}
+
+	// Collector.
+	go func() {
+		defer close(out)
+
+		for result := range pending {
+			var u U
+			select {
+			case <-ctx.Done():
+				stop.cancelled(ctx)
+				return
+			case u = <-result:
+			}
+
+			select {
+			case <-ctx.Done():
+				stop.cancelled(ctx)
+				return
+			case out <- u:
+			}
+		}
+	}()
+
+	return out, stop.Err
+}
+
+/**
+ * Like ParallelMap, but streams the results as soon as they're ready, regardless of input order.
+ */
+func ParallelMapUnordered[T any, U any](ctx context.Context, in <-chan T, workers int, fn func(T) U) (<-chan U, func() error) {
+
+	if workers <= 0 {
+		panic("number of workers should be positive")
+	}
+
+	out := make(chan U)
+	var stop stopReason
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	work := func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				stop.cancelled(ctx)
+				return
+			case v, ok := <-in:
+				if !ok {
+					stop.inputClosed(ctx)
+					return
+				}
+				select {
+				case <-ctx.Done():
+					stop.cancelled(ctx)
+					return
+				case out <- fn(v):
+				}
+			}
+		}
+	}
+
+	for i := 0; i < workers; i++ {
+		go work()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out, stop.Err
+}
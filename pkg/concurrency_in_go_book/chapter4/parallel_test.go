@@ -0,0 +1,125 @@
+package chapter4
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+)
+
+type parallelMap func(ctx context.Context, in <-chan int, workers int, fn func(int) int) (<-chan int, func() error)
+
+var parallelMaps = map[string]parallelMap{
+	"ParallelMap":          ParallelMap[int, int],
+	"ParallelMapUnordered": ParallelMapUnordered[int, int],
+}
+
+// Sleeps for a random while, so that results come back out of order.
+func slowSquare(seed int64, n int) func(i int) int {
+	r := rand.New(rand.NewSource(seed))
+	delays := make([]time.Duration, n)
+	for i := range delays {
+		delays[i] = time.Duration(r.Intn(500)) * time.Microsecond
+	}
+
+	return func(i int) int {
+		time.Sleep(delays[i])
+		return i * i
+	}
+}
+
+func upTo(n int) []int {
+	vals := make([]int, n)
+	for i := range vals {
+		vals[i] = i
+	}
+	return vals
+}
+
+func TestParallelMapResults(t *testing.T) {
+
+	const numItems, workers = 200, 8
+
+	for name, pm := range parallelMaps {
+		baseline := runtime.NumGoroutine()
+		done := make(chan interface{})
+
+		out, err := pm(context.Background(), IntStream(done, upTo(numItems)...), workers, slowSquare(1, numItems))
+		var got []int
+		for v := range out {
+			got = append(got, v)
+		}
+		close(done)
+
+		if err() != nil {
+			t.Errorf("%s stopped with %v, expected no error", name, err())
+		}
+		if len(got) != numItems {
+			t.Fatalf("%s gave %d results, expected %d", name, len(got), numItems)
+		}
+
+		// Only the ordered map promises the input order.
+		if name != "ParallelMap" {
+			sort.Ints(got)
+		}
+		for i, v := range got {
+			if v != i*i {
+				t.Fatalf("%s result %d is %d, expected %d", name, i, v, i*i)
+			}
+		}
+
+		checkGoroutines(t, baseline)
+	}
+}
+
+// An input that closes after ctx is done most likely closed because of the cancel,
+// so the map was cancelled rather than completed.
+func TestParallelMapInputClosedAfterCancel(t *testing.T) {
+
+	for name, pm := range parallelMaps {
+		baseline := runtime.NumGoroutine()
+
+		for i := 0; i < 100; i++ {
+			ctx, cancel := context.WithCancel(context.Background())
+			in := make(chan int)
+
+			out, err := pm(ctx, in, 4, func(i int) int { return i })
+			cancel()
+			close(in)
+			drain(out)
+
+			if err() != context.Canceled {
+				t.Fatalf("%s stopped with %v, expected %v", name, err(), context.Canceled)
+			}
+		}
+
+		checkGoroutines(t, baseline)
+	}
+}
+
+// Cancelling part way through stops every stage, however far behind the consumer is.
+func TestParallelMapCancelled(t *testing.T) {
+
+	for name, pm := range parallelMaps {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		ones, _ := RepeatContext(ctx, 1)
+		out, err := pm(ctx, ones, 4, func(i int) int { return i })
+		for i := 0; i < 10; i++ {
+			<-out
+		}
+
+		cancel()
+		drain(out)
+		drain(ones)
+
+		if err() != context.Canceled {
+			t.Errorf("%s stopped with %v, expected %v", name, err(), context.Canceled)
+		}
+
+		checkGoroutines(t, baseline)
+	}
+}
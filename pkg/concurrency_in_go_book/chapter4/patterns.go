@@ -2,14 +2,14 @@ package chapter4
 
 import "sync"
 
-var FanIn = func(done <-chan interface{}, channels ...<-chan interface{}) <-chan interface{} {
+func FanIn[T any](done <-chan interface{}, channels ...<-chan T) <-chan T {
 
-	out := make(chan interface{})
+	out := make(chan T)
 
 	var wg sync.WaitGroup
 	wg.Add(len(channels))
 
-	multiplex := func(c <-chan interface{}) {
+	multiplex := func(c <-chan T) {
 		defer wg.Done()
 		for i := range c {
 			select {
@@ -31,9 +31,9 @@ var FanIn = func(done <-chan interface{}, channels ...<-chan interface{}) <-chan
 	return out
 }
 
-var OrDone = func(done, c <-chan interface{}) <-chan interface{} {
+func OrDone[T any](done <-chan interface{}, c <-chan T) <-chan T {
 
-	out := make(chan interface{})
+	out := make(chan T)
 	go func() {
 		defer close(out)
 		for {
@@ -54,10 +54,10 @@ var OrDone = func(done, c <-chan interface{}) <-chan interface{} {
 	return out
 }
 
-var Tee = func(done, c <-chan interface{}) (<-chan interface{}, <-chan interface{}) {
+func Tee[T any](done <-chan interface{}, c <-chan T) (<-chan T, <-chan T) {
 
-	out1 := make(chan interface{})
-	out2 := make(chan interface{})
+	out1 := make(chan T)
+	out2 := make(chan T)
 
 	go func() {
 		defer close(out1)
@@ -81,14 +81,14 @@ var Tee = func(done, c <-chan interface{}) (<-chan interface{}, <-chan interface
 	return out1, out2
 }
 
-var Bridge = func(done <-chan interface{}, chanStream <-chan <-chan interface{}) <-chan interface{} {
-	out := make(chan interface{})
+func Bridge[T any](done <-chan interface{}, chanStream <-chan <-chan T) <-chan T {
+	out := make(chan T)
 
 	go func() {
 		defer close(out)
 
 		for {
-			var valStream <-chan interface{}
+			var valStream <-chan T
 			select {
 			case <-done:
 				return
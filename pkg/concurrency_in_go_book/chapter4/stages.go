@@ -0,0 +1,167 @@
+package chapter4
+
+/**
+ * A pre-emptible read-only channel that streams the result of applying fn to each value from the input stream.
+ */
+func Map[T any, U any](done <-chan interface{}, in <-chan T, fn func(T) U) <-chan U {
+
+	out := make(chan U)
+	go func() {
+		defer close(out)
+		for v := range OrDone(done, in) {
+			select {
+			case <-done:
+				return
+			case out <- fn(v):
+			}
+		}
+	}()
+	return out
+}
+
+/**
+ * A pre-emptible read-only channel that streams only the values from the input stream that satisfy keep.
+ */
+func Filter[T any](done <-chan interface{}, in <-chan T, keep func(T) bool) <-chan T {
+
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for v := range OrDone(done, in) {
+			if !keep(v) {
+				continue
+			}
+			select {
+			case <-done:
+				return
+			case out <- v:
+			}
+		}
+	}()
+	return out
+}
+
+/**
+ * A pre-emptible read-only channel that folds the input stream into a single value, starting from initial.
+ * Streams the result once the input stream closes. Closes without a result if pre-empted first.
+ */
+func Reduce[T any, U any](done <-chan interface{}, in <-chan T, initial U, fn func(U, T) U) <-chan U {
+
+	out := make(chan U)
+	go func() {
+		defer close(out)
+
+		acc := initial
+		for {
+			select {
+			case <-done:
+				return
+			case v, ok := <-in:
+				if !ok {
+					select {
+					case <-done:
+					case out <- acc:
+					}
+					return
+				}
+				acc = fn(acc, v)
+			}
+		}
+	}()
+	return out
+}
+
+/**
+ * A pre-emptible read-only channel that groups the input stream into slices of 'size' values.
+ * The last batch may be smaller, if the input stream closes part way through it.
+ */
+func Batch[T any](done <-chan interface{}, in <-chan T, size int) <-chan []T {
+
+	if size <= 0 {
+		panic("batch size should be positive")
+	}
+
+	out := make(chan []T)
+	go func() {
+		defer close(out)
+
+		batch := make([]T, 0, size)
+		for v := range OrDone(done, in) {
+			batch = append(batch, v)
+			if len(batch) < size {
+				continue
+			}
+
+			select {
+			case <-done:
+				return
+			case out <- batch:
+			}
+			batch = make([]T, 0, size)
+		}
+
+		// The loop also ends when done is closed. Only flush the partial batch if it ended
+		// because the input ran out, since select picks at random when both cases are ready.
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if len(batch) == 0 {
+			return
+		}
+		select {
+		case <-done:
+		case out <- batch:
+		}
+	}()
+	return out
+}
+
+/**
+ * A pre-emptible read-only channel that streams the input stream, minus its first 'n' values.
+ */
+func Skip[T any](done <-chan interface{}, in <-chan T, n int) <-chan T {
+
+	out := make(chan T)
+	go func() {
+		defer close(out)
+
+		skipped := 0
+		for v := range OrDone(done, in) {
+			if skipped < n {
+				skipped++
+				continue
+			}
+			select {
+			case <-done:
+				return
+			case out <- v:
+			}
+		}
+	}()
+	return out
+}
+
+/**
+ * A pre-emptible read-only channel that streams values from the input stream until one fails keep.
+ * That value, and everything after it, is dropped.
+ */
+func TakeWhile[T any](done <-chan interface{}, in <-chan T, keep func(T) bool) <-chan T {
+
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for v := range OrDone(done, in) {
+			if !keep(v) {
+				return
+			}
+			select {
+			case <-done:
+				return
+			case out <- v:
+			}
+		}
+	}()
+	return out
+}
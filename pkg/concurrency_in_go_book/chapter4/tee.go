@@ -0,0 +1,134 @@
+package chapter4
+
+import (
+	"fmt"
+	"reflect"
+)
+
+/**
+ * What TeeNWithOutputs does with a value for an output whose buffer is full.
+ */
+type TeePolicy int
+
+const (
+	/**
+	 * Hold back the whole stream until the output's consumer catches up. With a buffer,
+	 * the consumer can lag behind by that many values before that happens.
+	 */
+	Block TeePolicy = iota
+
+	/**
+	 * Drop the value for this output only.
+	 */
+	DropNewest
+
+	/**
+	 * Drop the oldest buffered value for this output to make room. With no buffer, there is nothing
+	 * to drop, so the value is dropped instead, unless the consumer is ready for it.
+	 */
+	DropOldest
+)
+
+/**
+ * Configures one of the outputs of TeeNWithOutputs. The zero value is an unbuffered, blocking output.
+ */
+type TeeOutput struct {
+	Buffer int
+	Policy TeePolicy
+}
+
+/**
+ * Like Tee, but splits the input stream into n outputs. Every value is streamed on every output,
+ * and the next value is only read once every output has taken the current one.
+ */
+func TeeN[T any](done <-chan interface{}, in <-chan T, n int) []<-chan T {
+	return TeeNWithOutputs(done, in, make([]TeeOutput, n))
+}
+
+/**
+ * Splits the input stream into one output per element of outputs, each with its own buffer size
+ * and policy for when its consumer is too slow. Slow consumers on dropping outputs don't hold back
+ * the others. Panics if any buffer size is negative, or any policy is not one of the above.
+ */
+func TeeNWithOutputs[T any](done <-chan interface{}, in <-chan T, outputs []TeeOutput) []<-chan T {
+
+	chans := make([]chan T, len(outputs))
+	readOnly := make([]<-chan T, len(outputs))
+	for i, o := range outputs {
+		if o.Buffer < 0 {
+			panic("buffer size should be non-negative")
+		}
+		switch o.Policy {
+		case Block, DropNewest, DropOldest:
+		default:
+			panic(fmt.Sprintf("unknown tee policy %d", o.Policy))
+		}
+		chans[i] = make(chan T, o.Buffer)
+		readOnly[i] = chans[i]
+	}
+
+	go func() {
+		defer func() {
+			for _, c := range chans {
+				close(c)
+			}
+		}()
+
+		// Sends to the blocking outputs still waiting for the current value, after done.
+		// The number of outputs isn't known up front, so this needs reflect.Select.
+		cases := []reflect.SelectCase{{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(done)}}
+
+		for v := range OrDone(done, in) {
+			// Going through a pointer keeps the value typed, even if T is an interface and v is nil.
+			val := reflect.ValueOf(&v).Elem()
+
+			cases = cases[:1]
+			for i, o := range outputs {
+				switch o.Policy {
+				case Block:
+					cases = append(cases, reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(chans[i]), Send: val})
+				case DropNewest:
+					select {
+					case chans[i] <- v:
+					default:
+					}
+				case DropOldest:
+					sendDroppingOldest(chans[i], v)
+				}
+			}
+
+			// Like Tee, send to whichever blocking output is ready first, so that outputs can be
+			// read in any order.
+			for len(cases) > 1 {
+				chosen, _, _ := reflect.Select(cases)
+				if chosen == 0 {
+					return
+				}
+				cases = append(cases[:chosen], cases[chosen+1:]...)
+			}
+		}
+	}()
+
+	return readOnly
+}
+
+func sendDroppingOldest[T any](c chan T, v T) {
+	for {
+		select {
+		case c <- v:
+			return
+		default:
+		}
+
+		select {
+		case <-c:
+			// Made room. Try again, though the consumer may well have made room too.
+		default:
+			// Either the consumer emptied the buffer in the meantime, so try again, or
+			// there's no buffer and nobody waiting, so drop the value.
+			if cap(c) == 0 {
+				return
+			}
+		}
+	}
+}
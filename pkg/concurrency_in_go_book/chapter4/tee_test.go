@@ -0,0 +1,16 @@
+package chapter4
+
+import "testing"
+
+func TestTeeNWithOutputsRejectsUnknownPolicy(t *testing.T) {
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("TeeNWithOutputs accepted an unknown policy")
+		}
+	}()
+
+	done := make(chan interface{})
+	defer close(done)
+	TeeNWithOutputs(done, make(chan int), []TeeOutput{{Policy: DropOldest + 1}})
+}
@@ -1,26 +1,26 @@
 package chapter4
 
-import "github.com/grsubramanian/go-playground/internal"
+import "github.com/grsubramanian/go-playground/pkg/semaphore"
 
 type LightSwitch interface {
-	Lock(s internal.Semaphore)
-	Unlock(s internal.Semaphore)
+	Lock(s *semaphore.Semaphore)
+	Unlock(s *semaphore.Semaphore)
 }
 
 type lightSwitchImpl struct {
 	count int
-	mutex internal.Semaphore
+	mutex *semaphore.Semaphore
 }
 
 func NewLightSwitch() LightSwitch {
-	m, _ := internal.NewSemaphore(1, 1)
+	m := semaphore.New(1)
 	return &lightSwitchImpl{
 		count: 0,
 		mutex: m,
 	}
 }
 
-func (l *lightSwitchImpl) Lock(s internal.Semaphore) {
+func (l *lightSwitchImpl) Lock(s *semaphore.Semaphore) {
 	l.mutex.Wait()
 	l.count++
 	if l.count == 1 {
@@ -29,7 +29,7 @@ func (l *lightSwitchImpl) Lock(s internal.Semaphore) {
 	l.mutex.Signal()
 }
 
-func (l *lightSwitchImpl) Unlock(s internal.Semaphore) {
+func (l *lightSwitchImpl) Unlock(s *semaphore.Semaphore) {
 	l.mutex.Wait()
 	l.count--
 	if l.count == 0 {
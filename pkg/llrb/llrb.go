@@ -0,0 +1,193 @@
+// Package llrb implements Sedgewick's left-leaning red-black tree, in which red links
+// only ever lean left. This makes it isomorphic to a 2-3 tree, and keeps insertion down
+// to three local fix-ups on the way back up.
+package llrb
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+type color int
+
+const (
+	red color = iota
+	black
+)
+
+type node[T constraints.Ordered] struct {
+	children [2]*node[T]
+
+	color color
+
+	data T
+}
+
+func isRed[T constraints.Ordered](nd *node[T]) bool {
+	return nd != nil && nd.color == red
+}
+
+// Tree is an ordered set of values. The zero value is an empty tree.
+type Tree[T constraints.Ordered] struct {
+	root *node[T]
+
+	size      int
+	rotations int
+}
+
+func (t *Tree[T]) Insert(obj T) {
+	t.root = t.insert(t.root, obj)
+
+	// It's always safe to make the root node black.
+	t.root.color = black
+}
+
+func (t *Tree[T]) insert(nd *node[T], obj T) *node[T] {
+
+	if nd == nil {
+		t.size++
+		return &node[T]{color: red, data: obj}
+	}
+
+	if obj < nd.data {
+		nd.children[0] = t.insert(nd.children[0], obj)
+	} else if obj > nd.data {
+		nd.children[1] = t.insert(nd.children[1], obj)
+	} else {
+		// No op. Object already exists.
+		return nd
+	}
+
+	// Red right link. Make it lean left.
+	if isRed(nd.children[1]) && !isRed(nd.children[0]) {
+		nd = t.rotate(nd, 0)
+	}
+
+	// Two red links in a row on the left. Balance them out into a temporary 4-node.
+	if isRed(nd.children[0]) && isRed(nd.children[0].children[0]) {
+		nd = t.rotate(nd, 1)
+	}
+
+	// Split the 4-node, passing the red link up.
+	if isRed(nd.children[0]) && isRed(nd.children[1]) {
+		nd.color = red
+		nd.children[0].color = black
+		nd.children[1].color = black
+	}
+
+	return nd
+}
+
+// Rotates the child of nd on the side opposite to dir up, in the direction dir.
+// i.e. dir = 0 is a left rotation, and dir = 1 is a right rotation.
+// Returns the new root of the subtree.
+func (t *Tree[T]) rotate(nd *node[T], dir int) *node[T] {
+	t.rotations++
+
+	x := nd.children[1-dir]
+	nd.children[1-dir] = x.children[dir]
+	x.children[dir] = nd
+	x.color = nd.color
+	nd.color = red
+	return x
+}
+
+func (t *Tree[T]) Contains(obj T) bool {
+	nd := t.root
+	for nd != nil {
+		if obj < nd.data {
+			nd = nd.children[0]
+		} else if obj > nd.data {
+			nd = nd.children[1]
+		} else {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Tree[T]) Len() int {
+	return t.size
+}
+
+func (nd *node[T]) height() int {
+	if nd == nil {
+		return 0
+	}
+
+	h := nd.children[0].height()
+	if hr := nd.children[1].height(); hr > h {
+		h = hr
+	}
+	return h + 1
+}
+
+// Returns the number of nodes on the longest path from the root down to a leaf.
+func (t *Tree[T]) Height() int {
+	return t.root.height()
+}
+
+// Returns the number of rotations performed since the tree was created.
+func (t *Tree[T]) Rotations() int {
+	return t.rotations
+}
+
+// Validates sort order, and the left-leaning red-black invariants.
+// Returns the number of nodes and the black height of the subtree.
+func (nd *node[T]) validate(lo *T, hi *T) (int, int, error) {
+
+	if nd == nil {
+		return 0, 1, nil
+	}
+
+	if (lo != nil && nd.data <= *lo) || (hi != nil && nd.data >= *hi) {
+		return 0, 0, fmt.Errorf("node %v is out of order", nd.data)
+	}
+
+	if isRed(nd.children[1]) {
+		return 0, 0, fmt.Errorf("node %v has a red right child %v", nd.data, nd.children[1].data)
+	}
+
+	if isRed(nd) && isRed(nd.children[0]) {
+		return 0, 0, fmt.Errorf("node %v and its child %v are both colored red", nd.data, nd.children[0].data)
+	}
+
+	nl, bhl, err := nd.children[0].validate(lo, &nd.data)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	nr, bhr, err := nd.children[1].validate(&nd.data, hi)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if bhl != bhr {
+		return 0, 0, fmt.Errorf("not all paths under node %v have the same black depth, left depth = %d, right depth = %d", nd.data, bhl, bhr)
+	}
+
+	bh := bhl
+	if nd.color == black {
+		bh++
+	}
+	return nl + nr + 1, bh, nil
+}
+
+func (t *Tree[T]) CheckInvariants() error {
+
+	if isRed(t.root) {
+		return fmt.Errorf("root is not colored black")
+	}
+
+	n, _, err := t.root.validate(nil, nil)
+	if err != nil {
+		return err
+	}
+
+	if n != t.size {
+		return fmt.Errorf("tree has %d nodes, but records a size of %d", n, t.size)
+	}
+
+	return nil
+}
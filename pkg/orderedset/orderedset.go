@@ -0,0 +1,24 @@
+// Package orderedset defines the interface shared by the balanced search trees in this
+// repository. Package orderedsettest has a conformance suite that can be run against any of them.
+package orderedset
+
+// OrderedSet is a set of values kept in sorted order.
+type OrderedSet[T any] interface {
+	// Inserts the object. No op if the object already exists.
+	Insert(obj T)
+
+	Contains(obj T) bool
+
+	// Returns a non-nil error if the internal structure of the set is broken.
+	CheckInvariants() error
+}
+
+// Balanced is implemented by ordered sets that are backed by a self-balancing tree,
+// and exposes statistics that are useful for comparing how they behave under a workload.
+type Balanced interface {
+	// Number of nodes on the longest path from the root down to a leaf.
+	Height() int
+
+	// Number of rotations performed since the set was created.
+	Rotations() int
+}
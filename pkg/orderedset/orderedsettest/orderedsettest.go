@@ -0,0 +1,116 @@
+// Package orderedsettest implements a conformance suite for ordered sets, to be run from the tests
+// of each implementation.
+package orderedsettest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/grsubramanian/go-playground/pkg/orderedset"
+)
+
+const seed = 1
+
+// Workload generates the sequence of keys to insert into a set, as indices in [1, n].
+type Workload struct {
+	Name string
+
+	Generate func(n int, r *rand.Rand) []int
+}
+
+// Workloads are the insertion orders that the conformance suite runs.
+var Workloads = []Workload{
+	{
+		Name: "ascending",
+		Generate: func(n int, r *rand.Rand) []int {
+			idxs := make([]int, n)
+			for i := range idxs {
+				idxs[i] = i + 1
+			}
+			return idxs
+		},
+	},
+	{
+		Name: "descending",
+		Generate: func(n int, r *rand.Rand) []int {
+			idxs := make([]int, n)
+			for i := range idxs {
+				idxs[i] = n - i
+			}
+			return idxs
+		},
+	},
+	{
+		// Alternates between the smallest and the largest remaining key.
+		Name: "zigzag",
+		Generate: func(n int, r *rand.Rand) []int {
+			idxs := make([]int, n)
+			lo, hi := 1, n
+			for i := range idxs {
+				if i%2 == 0 {
+					idxs[i] = lo
+					lo++
+				} else {
+					idxs[i] = hi
+					hi--
+				}
+			}
+			return idxs
+		},
+	},
+	{
+		// Draws n times from about n/2 keys, so about 57% of inserts are duplicates.
+		Name: "random",
+		Generate: func(n int, r *rand.Rand) []int {
+			idxs := make([]int, n)
+			for i := range idxs {
+				idxs[i] = 1 + r.Intn(n/2+1)
+			}
+			return idxs
+		},
+	},
+}
+
+// Runs every workload as a subtest, with n inserts each, against a fresh set obtained from newSet.
+// key maps the indices used by workloads to keys, and must be strictly increasing over [0, n + 1].
+//
+// After every insert, the invariants of the set are checked, along with membership of the inserted
+// key. At the end, membership of every key inserted, and of its neighbours, is checked against a
+// reference map. The height and rotations of sets that implement orderedset.Balanced are logged.
+func Run[T any](t *testing.T, newSet func() orderedset.OrderedSet[T], key func(i int) T, n int) {
+	for _, w := range Workloads {
+		w := w
+		t.Run(w.Name, func(t *testing.T) {
+			s := newSet()
+			idxs := w.Generate(n, rand.New(rand.NewSource(seed)))
+
+			expected := map[int]bool{}
+			for op, i := range idxs {
+				s.Insert(key(i))
+				expected[i] = true
+
+				err := s.CheckInvariants()
+				if err != nil {
+					t.Fatalf("after inserting %v (op %d): %v", key(i), op, err)
+				}
+
+				if !s.Contains(key(i)) {
+					t.Fatalf("after inserting %v (op %d): key not found", key(i), op)
+				}
+			}
+
+			// Check everything at the end too, including keys that were never inserted.
+			for _, i := range idxs {
+				for _, probe := range []int{i - 1, i, i + 1} {
+					if got := s.Contains(key(probe)); got != expected[probe] {
+						t.Fatalf("Contains(%v) = %v, expected %v", key(probe), got, expected[probe])
+					}
+				}
+			}
+
+			if b, ok := s.(orderedset.Balanced); ok {
+				t.Logf("%d distinct keys, height %d, %d rotations", len(expected), b.Height(), b.Rotations())
+			}
+		})
+	}
+}
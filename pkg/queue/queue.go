@@ -0,0 +1,228 @@
+// Package queue implements a bounded, thread-safe FIFO queue that blocks producers
+// when it's full and consumers when it's empty.
+package queue
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClosed is returned when putting into a closed queue, or taking from a closed queue
+// that has been drained.
+var ErrClosed = errors.New("queue: closed")
+
+// BlockingQueue is a bounded FIFO queue, backed by a ring buffer. It's safe for concurrent use.
+// Create one with NewBlockingQueue.
+type BlockingQueue[T any] struct {
+	mu sync.Mutex
+
+	// Items live in buf[head], buf[head+1], ... wrapping around, n of them.
+	buf  []T
+	head int
+	n    int
+
+	closed bool
+
+	// Goroutines blocked waiting for room or for an item respectively, in FIFO order. Each one
+	// waits on its own channel. Taking or putting an item closes the channel of the waiter at
+	// the front, which wakes up just that one to try again.
+	putWaiters  list.List
+	takeWaiters list.List
+}
+
+// Creates a new queue that holds at most capacity items. Panics if capacity is not positive.
+func NewBlockingQueue[T any](capacity int) *BlockingQueue[T] {
+	if capacity <= 0 {
+		panic("queue: capacity should be positive")
+	}
+
+	return &BlockingQueue[T]{buf: make([]T, capacity)}
+}
+
+// Adds the item to the back of the queue, blocking until there's room.
+// Returns ErrClosed if the queue is closed.
+func (q *BlockingQueue[T]) Put(item T) error {
+	return q.PutContext(context.Background(), item)
+}
+
+// Adds the item to the back of the queue if there's room, without blocking.
+// Returns false if the queue is full or closed.
+func (q *BlockingQueue[T]) TryPut(item T) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed || q.n == len(q.buf) {
+		return false
+	}
+
+	q.push(item)
+	return true
+}
+
+// Adds the item to the back of the queue, blocking until there's room or ctx is done.
+// Returns ErrClosed if the queue is closed, and ctx.Err() if ctx is done first.
+func (q *BlockingQueue[T]) PutContext(ctx context.Context, item T) error {
+	for {
+		q.mu.Lock()
+
+		if q.closed {
+			q.mu.Unlock()
+			return ErrClosed
+		}
+
+		if q.n < len(q.buf) {
+			q.push(item)
+			q.mu.Unlock()
+			return nil
+		}
+
+		err := q.wait(ctx, &q.putWaiters)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Removes the item at the front of the queue, blocking until there is one.
+// Once the queue is closed, the remaining items can still be taken. Returns false
+// if the queue is closed and drained.
+func (q *BlockingQueue[T]) Take() (T, bool) {
+	item, err := q.TakeContext(context.Background())
+	return item, err == nil
+}
+
+// Removes the item at the front of the queue if there is one, without blocking.
+// Returns false if the queue is empty.
+func (q *BlockingQueue[T]) TryTake() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.n == 0 {
+		var nilVal T
+		return nilVal, false
+	}
+
+	return q.pop(), true
+}
+
+// Removes the item at the front of the queue, blocking until there is one or ctx is done.
+// Returns ErrClosed if the queue is closed and drained, and ctx.Err() if ctx is done first.
+func (q *BlockingQueue[T]) TakeContext(ctx context.Context) (T, error) {
+	for {
+		q.mu.Lock()
+
+		if q.n > 0 {
+			item := q.pop()
+			q.mu.Unlock()
+			return item, nil
+		}
+
+		if q.closed {
+			q.mu.Unlock()
+			var nilVal T
+			return nilVal, ErrClosed
+		}
+
+		err := q.wait(ctx, &q.takeWaiters)
+		if err != nil {
+			var nilVal T
+			return nilVal, err
+		}
+	}
+}
+
+// Closes the queue. Further puts fail, and takes fail once the remaining items are drained.
+// Everybody blocked on the queue is woken up. Closing a closed queue is a no op.
+func (q *BlockingQueue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return
+	}
+
+	q.closed = true
+	for wakeOne(&q.putWaiters) {
+	}
+	for wakeOne(&q.takeWaiters) {
+	}
+}
+
+// Returns the number of items in the queue.
+func (q *BlockingQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.n
+}
+
+// Returns the maximum number of items the queue can hold.
+func (q *BlockingQueue[T]) Cap() int {
+	// The buffer never changes size, so no need for the lock.
+	return len(q.buf)
+}
+
+func (q *BlockingQueue[T]) push(item T) {
+	// Must be called with the lock held, when there's room.
+
+	q.buf[(q.head+q.n)%len(q.buf)] = item
+	q.n++
+	wakeOne(&q.takeWaiters)
+}
+
+func (q *BlockingQueue[T]) pop() T {
+	// Must be called with the lock held, when there's an item.
+
+	item := q.buf[q.head]
+
+	// Don't hold on to the item, so that it can be garbage collected.
+	var nilVal T
+	q.buf[q.head] = nilVal
+
+	q.head = (q.head + 1) % len(q.buf)
+	q.n--
+	wakeOne(&q.putWaiters)
+	return item
+}
+
+// Queues up on waiters, and blocks until woken up or ctx is done. Must be called with
+// the lock held, and returns with it released.
+func (q *BlockingQueue[T]) wait(ctx context.Context, waiters *list.List) error {
+	ready := make(chan struct{})
+	el := waiters.PushBack(ready)
+	q.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	select {
+	case <-ready:
+		// We were woken up after ctx was done, but before we got the lock. Pass the
+		// wakeup on, so that it isn't lost.
+		wakeOne(waiters)
+	default:
+		waiters.Remove(el)
+	}
+	return ctx.Err()
+}
+
+// Wakes up the waiter at the front, if any. Returns whether there was one.
+// Must be called with the lock held.
+func wakeOne(waiters *list.List) bool {
+	front := waiters.Front()
+	if front == nil {
+		return false
+	}
+
+	waiters.Remove(front)
+	close(front.Value.(chan struct{}))
+	return true
+}
@@ -0,0 +1,217 @@
+package queue
+
+import (
+	"container/list"
+	"context"
+	"testing"
+	"time"
+)
+
+// Waits for n goroutines to be queued up on waiters, failing if they aren't within a second.
+func waitForWaiters[T any](t *testing.T, q *BlockingQueue[T], waiters *list.List, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		q.mu.Lock()
+		got := waiters.Len()
+		q.mu.Unlock()
+
+		if got == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d waiters queued up, expected %d", got, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Returns the channels the goroutines queued up on waiters are blocked on.
+func waiterChans[T any](q *BlockingQueue[T], waiters *list.List) []chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var chans []chan struct{}
+	for el := waiters.Front(); el != nil; el = el.Next() {
+		chans = append(chans, el.Value.(chan struct{}))
+	}
+	return chans
+}
+
+// Returns how many of the channels are closed, that is how many of their waiters were woken up.
+func numWoken(chans []chan struct{}) int {
+	n := 0
+	for _, c := range chans {
+		select {
+		case <-c:
+			n++
+		default:
+		}
+	}
+	return n
+}
+
+type takeResult struct {
+	item int
+	err  error
+}
+
+func takeAsync(ctx context.Context, q *BlockingQueue[int]) <-chan takeResult {
+	res := make(chan takeResult, 1)
+	go func() {
+		item, err := q.TakeContext(ctx)
+		res <- takeResult{item, err}
+	}()
+	return res
+}
+
+func TestWrapAround(t *testing.T) {
+	q := NewBlockingQueue[int](3)
+
+	next, expected := 0, 0
+	for round := 0; round < 10; round++ {
+		for q.TryPut(next) {
+			next++
+		}
+		if q.Len() != q.Cap() {
+			t.Fatalf("queue holds %d items once TryPut fails, expected %d", q.Len(), q.Cap())
+		}
+
+		// Take fewer than were put, so that head keeps moving around the ring buffer.
+		for i := 0; i < 2; i++ {
+			item, ok := q.TryTake()
+			if !ok || item != expected {
+				t.Fatalf("TryTake returned (%d, %v), expected (%d, true)", item, ok, expected)
+			}
+			expected++
+		}
+	}
+}
+
+// A put wakes a single taker, rather than every blocked one.
+func TestPutWakesOneTaker(t *testing.T) {
+	q := NewBlockingQueue[int](1)
+
+	var results []<-chan takeResult
+	for i := 0; i < 3; i++ {
+		results = append(results, takeAsync(context.Background(), q))
+	}
+	waitForWaiters(t, q, &q.takeWaiters, 3)
+	chans := waiterChans(q, &q.takeWaiters)
+
+	q.Put(1)
+	if n := numWoken(chans); n != 1 {
+		t.Errorf("put woke up %d takers, expected 1", n)
+	}
+
+	// Exactly one taker gets the item. The others stay blocked until the queue is closed.
+	q.Close()
+	got := 0
+	for _, res := range results {
+		r := <-res
+		if r.err == nil {
+			got++
+		} else if r.err != ErrClosed {
+			t.Errorf("TakeContext returned %v, expected %v", r.err, ErrClosed)
+		}
+	}
+	if got != 1 {
+		t.Errorf("%d takers got the item, expected 1", got)
+	}
+}
+
+// A take wakes a single putter, rather than every blocked one.
+func TestTakeWakesOnePutter(t *testing.T) {
+	q := NewBlockingQueue[int](1)
+	q.Put(0)
+
+	for i := 1; i <= 3; i++ {
+		go q.Put(i)
+	}
+	waitForWaiters(t, q, &q.putWaiters, 3)
+	chans := waiterChans(q, &q.putWaiters)
+
+	q.Take()
+	if n := numWoken(chans); n != 1 {
+		t.Errorf("take woke up %d putters, expected 1", n)
+	}
+
+	q.Close()
+}
+
+// A waiter that's woken up after its ctx is done hands the wakeup on to the next waiter,
+// so that the item isn't left stranded in the queue.
+func TestCancelAfterWakeupPassesItOn(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		q := NewBlockingQueue[int](1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		first := takeAsync(ctx, q)
+		waitForWaiters(t, q, &q.takeWaiters, 1)
+		second := takeAsync(context.Background(), q)
+		waitForWaiters(t, q, &q.takeWaiters, 2)
+
+		// Cancel the first waiter, and wake it up before it can get the lock to give up its place.
+		q.mu.Lock()
+		cancel()
+		q.push(1)
+		q.mu.Unlock()
+
+		r := <-first
+		if r.err == nil {
+			// The first waiter hadn't blocked yet, and saw both at once. It may take the
+			// item then, which leaves the second one waiting.
+			q.Close()
+			if r := <-second; r.err != ErrClosed {
+				t.Fatalf("second TakeContext returned %v, expected %v", r.err, ErrClosed)
+			}
+			continue
+		}
+		if r.err != context.Canceled {
+			t.Fatalf("first TakeContext returned %v, expected %v", r.err, context.Canceled)
+		}
+		select {
+		case r := <-second:
+			if r.err != nil || r.item != 1 {
+				t.Fatalf("second TakeContext returned (%d, %v), expected (1, nil)", r.item, r.err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("wakeup was lost, and the item is stranded in the queue")
+		}
+	}
+}
+
+// Closing wakes up every blocked putter and taker.
+func TestCloseWakesEveryWaiter(t *testing.T) {
+	empty := NewBlockingQueue[int](1)
+	full := NewBlockingQueue[int](1)
+	full.Put(0)
+
+	errs := make(chan error, 6)
+	for i := 0; i < 3; i++ {
+		go func() {
+			_, err := empty.TakeContext(context.Background())
+			errs <- err
+		}()
+		go func(i int) {
+			errs <- full.Put(i)
+		}(i)
+	}
+	waitForWaiters(t, empty, &empty.takeWaiters, 3)
+	waitForWaiters(t, full, &full.putWaiters, 3)
+
+	empty.Close()
+	full.Close()
+
+	for i := 0; i < 6; i++ {
+		select {
+		case err := <-errs:
+			if err != ErrClosed {
+				t.Errorf("blocked call returned %v, expected %v", err, ErrClosed)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("only %d of 6 waiters were woken up by Close", i)
+		}
+	}
+}
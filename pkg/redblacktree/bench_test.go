@@ -0,0 +1,257 @@
+package redblacktree
+
+// Benchmarks Tree against simpler ordered set baselines, e.g.
+//
+//	go test ./pkg/redblacktree -run '^$' -bench 'Insert/random/n=100000$' -count 10 > new.txt
+//	benchstat old.txt new.txt
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/google/btree"
+)
+
+const benchSeed = 1
+
+var (
+	benchSizes = []int{1000, 10000, 100000, 1000000}
+	benchDists = []string{"sequential", "random", "adversarial"}
+	benchImpls = []string{"redblacktree", "btree", "sortedslice", "mapsort", "skiplist"}
+
+	// Skip the sorted slice beyond this many keys, since its inserts are O(n).
+	maxSortedSliceSize = 100000
+)
+
+// The ordered set operations being benchmarked.
+type benchSet interface {
+	Insert(key int)
+	Contains(key int) bool
+}
+
+// Google's B-tree, with a degree of 32.
+type bTree struct {
+	t *btree.BTreeG[int]
+}
+
+func newBTree() *bTree {
+	return &bTree{t: btree.NewOrderedG[int](32)}
+}
+
+func (b *bTree) Insert(key int) {
+	b.t.ReplaceOrInsert(key)
+}
+
+func (b *bTree) Contains(key int) bool {
+	return b.t.Has(key)
+}
+
+// Sorted slice, with binary search for lookups and inserts.
+type sortedSlice []int
+
+func (s *sortedSlice) Insert(key int) {
+	i := sort.SearchInts(*s, key)
+	if i < len(*s) && (*s)[i] == key {
+		return
+	}
+	*s = append(*s, 0)
+	copy((*s)[i+1:], (*s)[i:])
+	(*s)[i] = key
+}
+
+func (s *sortedSlice) Contains(key int) bool {
+	i := sort.SearchInts(*s, key)
+	return i < len(*s) && (*s)[i] == key
+}
+
+// Hash map for membership, with the keys sorted on demand when an ordered view is needed.
+type mapSort struct {
+	members map[int]struct{}
+	sorted  []int
+}
+
+func (m *mapSort) Insert(key int) {
+	if m.members == nil {
+		m.members = map[int]struct{}{}
+	}
+	m.members[key] = struct{}{}
+	m.sorted = nil
+}
+
+func (m *mapSort) Contains(key int) bool {
+	_, ok := m.members[key]
+	return ok
+}
+
+func (m *mapSort) Sorted() []int {
+	if m.sorted == nil {
+		m.sorted = make([]int, 0, len(m.members))
+		for key := range m.members {
+			m.sorted = append(m.sorted, key)
+		}
+		sort.Ints(m.sorted)
+	}
+	return m.sorted
+}
+
+const maxLevel = 32
+
+type skipNode struct {
+	key  int
+	next []*skipNode
+}
+
+// Skip list with a promotion probability of 1/2.
+type skipList struct {
+	head  skipNode
+	level int
+	r     *rand.Rand
+}
+
+func newSkipList() *skipList {
+	return &skipList{
+		head:  skipNode{next: make([]*skipNode, maxLevel)},
+		level: 1,
+		r:     rand.New(rand.NewSource(benchSeed)),
+	}
+}
+
+func (s *skipList) Insert(key int) {
+	var update [maxLevel]*skipNode
+	nd := &s.head
+	for l := s.level - 1; l >= 0; l-- {
+		for nd.next[l] != nil && nd.next[l].key < key {
+			nd = nd.next[l]
+		}
+		update[l] = nd
+	}
+	if nd.next[0] != nil && nd.next[0].key == key {
+		return
+	}
+
+	level := 1
+	for level < maxLevel && s.r.Int63()&1 == 0 {
+		level++
+	}
+	for ; s.level < level; s.level++ {
+		update[s.level] = &s.head
+	}
+
+	created := &skipNode{key: key, next: make([]*skipNode, level)}
+	for l := 0; l < level; l++ {
+		created.next[l] = update[l].next[l]
+		update[l].next[l] = created
+	}
+}
+
+func (s *skipList) Contains(key int) bool {
+	nd := &s.head
+	for l := s.level - 1; l >= 0; l-- {
+		for nd.next[l] != nil && nd.next[l].key < key {
+			nd = nd.next[l]
+		}
+	}
+	return nd.next[0] != nil && nd.next[0].key == key
+}
+
+func newBenchSet(impl string) benchSet {
+	switch impl {
+	case "redblacktree":
+		return &Tree[int]{}
+	case "btree":
+		return newBTree()
+	case "sortedslice":
+		return &sortedSlice{}
+	case "mapsort":
+		return &mapSort{}
+	case "skiplist":
+		return newSkipList()
+	}
+	panic(fmt.Sprintf("unknown implementation %q", impl))
+}
+
+// Generates n distinct keys in the order they are to be inserted.
+func benchKeys(dist string, n int) []int {
+	ks := make([]int, n)
+	switch dist {
+	case "sequential":
+		for i := range ks {
+			ks[i] = i
+		}
+	case "random":
+		ks = rand.New(rand.NewSource(benchSeed)).Perm(n)
+	case "adversarial":
+		// Alternate between the two ends of the range, closing in on the middle.
+		// Every insert lands next to the previous extreme on one side, forcing
+		// rebalancing at both edges of the tree.
+		for i := range ks {
+			if i%2 == 0 {
+				ks[i] = i / 2
+			} else {
+				ks[i] = n - 1 - i/2
+			}
+		}
+	default:
+		panic(fmt.Sprintf("unknown distribution %q", dist))
+	}
+	return ks
+}
+
+// Runs fn as a sub-benchmark for every distribution, size and implementation.
+func runBenchmarks(b *testing.B, fn func(b *testing.B, impl string, ks []int)) {
+	for _, dist := range benchDists {
+		b.Run(dist, func(b *testing.B) {
+			for _, n := range benchSizes {
+				b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+					ks := benchKeys(dist, n)
+					for _, impl := range benchImpls {
+						b.Run(impl, func(b *testing.B) {
+							if impl == "sortedslice" && n > maxSortedSliceSize {
+								b.Skip("sorted slice inserts are O(n)")
+							}
+							fn(b, impl, ks)
+						})
+					}
+				})
+			}
+		})
+	}
+}
+
+// Builds a whole set per op, so ns/op is for n inserts. Also reports the cost per key.
+func BenchmarkInsert(b *testing.B) {
+	runBenchmarks(b, func(b *testing.B, impl string, ks []int) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s := newBenchSet(impl)
+			for _, k := range ks {
+				s.Insert(k)
+			}
+			if ms, ok := s.(*mapSort); ok {
+				// The map only pays for ordering once all keys are in.
+				ms.Sorted()
+			}
+		}
+		b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N)/float64(len(ks)), "ns/key")
+	})
+}
+
+func BenchmarkContains(b *testing.B) {
+	runBenchmarks(b, func(b *testing.B, impl string, ks []int) {
+		s := newBenchSet(impl)
+		for _, k := range ks {
+			s.Insert(k)
+		}
+
+		// Probe for present and absent keys alike, in a random order.
+		probes := rand.New(rand.NewSource(benchSeed)).Perm(2 * len(ks))
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			s.Contains(probes[i%len(probes)])
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package redblacktree
+
+import (
+	"fmt"
+	"reflect"
+
+	"golang.org/x/exp/constraints"
+)
+
+func compareOrdered[T constraints.Ordered](a, b T) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+// Returns a comparator using the natural order of T.
+// Panics if T is not an ordered type.
+func naturalOrder[T any]() func(a, b T) int {
+
+	// Fast path for the predeclared ordered types.
+	var nilVal T
+	var cmp any
+	switch any(nilVal).(type) {
+	case int:
+		cmp = compareOrdered[int]
+	case int8:
+		cmp = compareOrdered[int8]
+	case int16:
+		cmp = compareOrdered[int16]
+	case int32:
+		cmp = compareOrdered[int32]
+	case int64:
+		cmp = compareOrdered[int64]
+	case uint:
+		cmp = compareOrdered[uint]
+	case uint8:
+		cmp = compareOrdered[uint8]
+	case uint16:
+		cmp = compareOrdered[uint16]
+	case uint32:
+		cmp = compareOrdered[uint32]
+	case uint64:
+		cmp = compareOrdered[uint64]
+	case uintptr:
+		cmp = compareOrdered[uintptr]
+	case float32:
+		cmp = compareOrdered[float32]
+	case float64:
+		cmp = compareOrdered[float64]
+	case string:
+		cmp = compareOrdered[string]
+	}
+	if cmp != nil {
+		return cmp.(func(a, b T) int)
+	}
+
+	// Types defined on top of the ordered types. Compare them by their underlying values.
+	typ := reflect.TypeOf(&nilVal).Elem()
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(a, b T) int {
+			return compareOrdered(reflect.ValueOf(a).Int(), reflect.ValueOf(b).Int())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(a, b T) int {
+			return compareOrdered(reflect.ValueOf(a).Uint(), reflect.ValueOf(b).Uint())
+		}
+	case reflect.Float32, reflect.Float64:
+		return func(a, b T) int {
+			return compareOrdered(reflect.ValueOf(a).Float(), reflect.ValueOf(b).Float())
+		}
+	case reflect.String:
+		return func(a, b T) int {
+			return compareOrdered(reflect.ValueOf(a).String(), reflect.ValueOf(b).String())
+		}
+	}
+
+	panic(fmt.Sprintf("redblacktree: %v is not an ordered type, use NewWithComparator", typ))
+}
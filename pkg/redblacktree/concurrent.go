@@ -0,0 +1,124 @@
+package redblacktree
+
+import "sync"
+
+// ConcurrentTree is a Tree that is safe for use by multiple goroutines.
+//
+// Writes are serialised, while reads and iterations run in parallel with each other.
+// The zero value is an empty tree ordering values by their natural order,
+// and is only usable when T is an ordered type.
+type ConcurrentTree[T any] struct {
+	mu sync.RWMutex
+	t  Tree[T]
+}
+
+// Creates an empty concurrent tree ordering values using cmp.
+func NewConcurrentWithComparator[T any](cmp func(a, b T) int) *ConcurrentTree[T] {
+	ct := &ConcurrentTree[T]{}
+	ct.t.cmp = cmp
+	return ct
+}
+
+// Note that reads never write to the tree, not even to resolve the natural order
+// comparator of a zero value tree, so they are safe to run under the read lock.
+
+func (ct *ConcurrentTree[T]) Insert(obj T) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.t.Insert(obj)
+}
+
+func (ct *ConcurrentTree[T]) Delete(obj T) bool {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.t.Delete(obj)
+}
+
+func (ct *ConcurrentTree[T]) Contains(obj T) bool {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return ct.t.Contains(obj)
+}
+
+func (ct *ConcurrentTree[T]) Len() int {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return ct.t.Len()
+}
+
+func (ct *ConcurrentTree[T]) Min() (T, bool) {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return ct.t.Min()
+}
+
+func (ct *ConcurrentTree[T]) Max() (T, bool) {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return ct.t.Max()
+}
+
+func (ct *ConcurrentTree[T]) Floor(obj T) (T, bool) {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return ct.t.Floor(obj)
+}
+
+func (ct *ConcurrentTree[T]) Ceiling(obj T) (T, bool) {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return ct.t.Ceiling(obj)
+}
+
+func (ct *ConcurrentTree[T]) Rank(obj T) int {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return ct.t.Rank(obj)
+}
+
+func (ct *ConcurrentTree[T]) Select(k int) (T, bool) {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return ct.t.Select(k)
+}
+
+// The iteration methods hold the read lock while calling fn, so fn must not
+// modify the tree. Use Snapshot for long running scans, to avoid holding up writers.
+
+func (ct *ConcurrentTree[T]) Ascend(fn func(obj T) bool) {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	ct.t.Ascend(fn)
+}
+
+func (ct *ConcurrentTree[T]) Descend(fn func(obj T) bool) {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	ct.t.Descend(fn)
+}
+
+func (ct *ConcurrentTree[T]) AscendRange(lo T, hi T, fn func(obj T) bool) {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	ct.t.AscendRange(lo, hi, fn)
+}
+
+func (ct *ConcurrentTree[T]) AscendGreaterOrEqual(pivot T, fn func(obj T) bool) {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	ct.t.AscendGreaterOrEqual(pivot, fn)
+}
+
+func (ct *ConcurrentTree[T]) CheckInvariants() error {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return ct.t.CheckInvariants()
+}
+
+// Returns a copy of the tree as of now. The copy is not affected by later writes
+// to the concurrent tree, and is owned by the caller, so it can be read without locking.
+func (ct *ConcurrentTree[T]) Snapshot() *Tree[T] {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return &Tree[T]{tree: ct.t.clone()}
+}
@@ -0,0 +1,128 @@
+package redblacktree
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Run with -race. A single Insert into an empty tree never compares anything, so reads
+// must not be the first to resolve the natural order comparator.
+func TestConcurrentReadsAfterSingleInsert(t *testing.T) {
+	ct := &ConcurrentTree[int]{}
+	ct.Insert(1)
+
+	snap := ct.Snapshot()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			if got := ct.Contains(i); got != (i == 1) {
+				t.Errorf("Contains(%d) = %v, want %v", i, got, i == 1)
+			}
+			if got := snap.Contains(i); got != (i == 1) {
+				t.Errorf("snapshot Contains(%d) = %v, want %v", i, got, i == 1)
+			}
+			ct.Floor(i)
+			ct.AscendRange(0, i, func(int) bool { return true })
+		}(i)
+	}
+	wg.Wait()
+}
+
+// Run with -race. Writers insert and delete, while readers scan the tree and snapshotters
+// keep checking snapshots that must not change under them.
+func TestConcurrentReadersAndWriters(t *testing.T) {
+	const (
+		keySpan    = 1000
+		numWriters = 4
+		numReaders = 4
+		duration   = 200 * time.Millisecond
+	)
+
+	ct := &ConcurrentTree[int]{}
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+
+	checkAscending := func(name string, scan func(fn func(val int) bool)) {
+		prev := -1
+		scan(func(val int) bool {
+			if val <= prev {
+				t.Errorf("%s yielded %d after %d", name, val, prev)
+				return false
+			}
+			prev = val
+			return true
+		})
+	}
+
+	writer := func(r *rand.Rand) {
+		defer wg.Done()
+		for !stop.Load() {
+			val := r.Intn(keySpan)
+			if r.Intn(2) == 0 {
+				ct.Insert(val)
+			} else {
+				ct.Delete(val)
+			}
+		}
+	}
+
+	reader := func(r *rand.Rand) {
+		defer wg.Done()
+		for !stop.Load() {
+			switch r.Intn(3) {
+			case 0:
+				ct.Contains(r.Intn(keySpan))
+			case 1:
+				checkAscending("Ascend", ct.Ascend)
+			case 2:
+				lo := r.Intn(keySpan)
+				checkAscending("AscendRange", func(fn func(val int) bool) {
+					ct.AscendRange(lo, lo+keySpan/10, fn)
+				})
+			}
+		}
+	}
+
+	snapshotter := func() {
+		defer wg.Done()
+		for !stop.Load() {
+			s := ct.Snapshot()
+			n := s.Len()
+			for i := 0; i < 10; i++ {
+				if err := s.CheckInvariants(); err != nil {
+					t.Errorf("snapshot: %v", err)
+					return
+				}
+				checkAscending("Snapshot.Ascend", s.Ascend)
+				if s.Len() != n {
+					t.Errorf("snapshot changed size from %d to %d", n, s.Len())
+					return
+				}
+			}
+		}
+	}
+
+	wg.Add(numWriters + numReaders + 1)
+	for i := 0; i < numWriters; i++ {
+		go writer(rand.New(rand.NewSource(int64(i))))
+	}
+	for i := 0; i < numReaders; i++ {
+		go reader(rand.New(rand.NewSource(int64(numWriters + i))))
+	}
+	go snapshotter()
+
+	time.Sleep(duration)
+	stop.Store(true)
+	wg.Wait()
+
+	if err := ct.CheckInvariants(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,61 @@
+package redblacktree
+
+import "testing"
+
+// Decodes the input into operations, two bytes each. The first picks the operation,
+// and the second is the value, as a signed byte. A trailing odd byte is ignored.
+// Every operation is applied to both a tree and a map. Membership has to agree, and
+// the tree has to pass CheckInvariants, after every operation.
+//
+// The seed corpus is in testdata/fuzz/FuzzTreeOps. Fuzz with
+//
+//	go test ./pkg/redblacktree -run '^$' -fuzz FuzzTreeOps
+//
+// and replay a single input in the corpus with
+//
+//	go test ./pkg/redblacktree -run FuzzTreeOps/<name>
+func FuzzTreeOps(f *testing.F) {
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tree := &Tree[int]{}
+		model := map[int]bool{}
+
+		for i := 0; i+1 < len(data); i += 2 {
+			val := int(int8(data[i+1]))
+
+			var op string
+			switch data[i] % 3 {
+			case 0:
+				op = "insert"
+				tree.Insert(val)
+				model[val] = true
+			case 1:
+				// Checked below, along with every other value.
+				op = "contains"
+			case 2:
+				op = "delete"
+				deleted := tree.Delete(val)
+				if deleted != model[val] {
+					t.Fatalf("op %d: delete %d returned %v, expected %v", i/2, val, deleted, model[val])
+				}
+				delete(model, val)
+			}
+
+			err := tree.CheckInvariants()
+			if err != nil {
+				t.Fatalf("op %d: %s %d: %v", i/2, op, val, err)
+			}
+
+			if tree.Len() != len(model) {
+				t.Fatalf("op %d: %s %d: tree has %d values, expected %d", i/2, op, val, tree.Len(), len(model))
+			}
+
+			for v := -128; v < 128; v++ {
+				if tree.Contains(v) != model[v] {
+					t.Fatalf("op %d: %s %d: contains %d is %v, expected %v", i/2, op, val, v, tree.Contains(v), model[v])
+				}
+			}
+		}
+	})
+}
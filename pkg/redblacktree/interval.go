@@ -0,0 +1,166 @@
+package redblacktree
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Interval is the closed interval [Lo, Hi].
+type Interval[T constraints.Ordered] struct {
+	Lo T
+	Hi T
+}
+
+func (iv Interval[T]) overlaps(lo T, hi T) bool {
+	return iv.Lo <= hi && lo <= iv.Hi
+}
+
+// Intervals are ordered by their low endpoints, and then by their high endpoints.
+func compareIntervals[T constraints.Ordered](a, b Interval[T]) int {
+	if c := compareOrdered(a.Lo, b.Lo); c != 0 {
+		return c
+	}
+	return compareOrdered(a.Hi, b.Hi)
+}
+
+// IntervalEntry is an interval along with its associated value.
+type IntervalEntry[T constraints.Ordered, V any] struct {
+	Interval[T]
+	Value V
+}
+
+// What an interval tree keeps at each node, besides the interval.
+type intervalValue[T constraints.Ordered, V any] struct {
+	value V
+
+	// Largest high endpoint in the node's subtree.
+	max T
+}
+
+// IntervalTree maps closed intervals to values, and answers which intervals overlap
+// a given interval or point in O(min(n, (k + 1) log n)) time, where k is the number of results.
+//
+// Each node is augmented with the largest high endpoint in its subtree, which is
+// kept up to date through inserts, deletes and rotations.
+//
+// The zero value is an empty interval tree.
+type IntervalTree[T constraints.Ordered, V any] struct {
+	t tree[Interval[T], intervalValue[T, V]]
+}
+
+func (it *IntervalTree[T, V]) core() *tree[Interval[T], intervalValue[T, V]] {
+	if it.t.augment == nil {
+		it.t.cmp = compareIntervals[T]
+		it.t.augment = augmentMax[T, V]
+	}
+	return &it.t
+}
+
+func augmentMax[T constraints.Ordered, V any](nd *node[Interval[T], intervalValue[T, V]]) {
+	nd.value.max = expectedMax(nd)
+}
+
+func expectedMax[T constraints.Ordered, V any](nd *node[Interval[T], intervalValue[T, V]]) T {
+	max := nd.data.Hi
+	for _, child := range nd.children {
+		if child != nil && child.value.max > max {
+			max = child.value.max
+		}
+	}
+	return max
+}
+
+// Associates value with the interval [lo, hi], replacing any value already associated with it.
+// Panics if lo > hi.
+func (it *IntervalTree[T, V]) Insert(lo T, hi T, value V) {
+	if lo > hi {
+		panic(fmt.Sprintf("redblacktree: invalid interval [%v, %v]", lo, hi))
+	}
+
+	nd, _ := it.core().insert(Interval[T]{Lo: lo, Hi: hi})
+	nd.value.value = value
+}
+
+// Deletes the interval [lo, hi]. Returns false if there was no such interval.
+func (it *IntervalTree[T, V]) Delete(lo T, hi T) bool {
+	return it.core().Delete(Interval[T]{Lo: lo, Hi: hi})
+}
+
+func (it *IntervalTree[T, V]) Len() int {
+	return it.t.Len()
+}
+
+// Returns the intervals overlapping [lo, hi], in ascending order.
+func (it *IntervalTree[T, V]) Overlapping(lo T, hi T) []IntervalEntry[T, V] {
+	var entries []IntervalEntry[T, V]
+	collectOverlapping(it.t.root, lo, hi, &entries)
+	return entries
+}
+
+// Returns the intervals containing point, in ascending order.
+func (it *IntervalTree[T, V]) Stab(point T) []IntervalEntry[T, V] {
+	return it.Overlapping(point, point)
+}
+
+func collectOverlapping[T constraints.Ordered, V any](nd *node[Interval[T], intervalValue[T, V]], lo T, hi T, entries *[]IntervalEntry[T, V]) {
+
+	// Nothing in the subtree reaches as far as lo.
+	if nd == nil || nd.value.max < lo {
+		return
+	}
+
+	collectOverlapping(nd.children[0], lo, hi, entries)
+
+	// The node, and everything in its right subtree, starts after hi.
+	if nd.data.Lo > hi {
+		return
+	}
+
+	if nd.data.overlaps(lo, hi) {
+		*entries = append(*entries, IntervalEntry[T, V]{Interval: nd.data, Value: nd.value.value})
+	}
+
+	collectOverlapping(nd.children[1], lo, hi, entries)
+}
+
+func validateMaxInvariant[T constraints.Ordered, V any](nd *node[Interval[T], intervalValue[T, V]]) error {
+
+	if nd == nil {
+		return nil
+	}
+
+	for _, child := range nd.children {
+		err := validateMaxInvariant(child)
+		if err != nil {
+			return err
+		}
+	}
+
+	expected := expectedMax(nd)
+	if nd.value.max != expected {
+		return &InvariantError{
+			Invariant: Augmentation,
+			Node:      nd.data,
+			Values:    []any{nd.value.max, expected},
+			msg:       fmt.Sprintf("node %v records max endpoint %v, but its subtree has max endpoint %v", nd.data, nd.value.max, expected),
+		}
+	}
+
+	return nil
+}
+
+// Validates the structure of the tree, along with the max endpoint recorded at each node.
+func (it *IntervalTree[T, V]) CheckInvariants() error {
+
+	err := it.core().CheckInvariants()
+	if err != nil {
+		return err
+	}
+
+	return validateMaxInvariant(it.t.root)
+}
+
+func (it *IntervalTree[T, V]) Print() {
+	it.t.Print()
+}
@@ -0,0 +1,67 @@
+package redblacktree
+
+import "fmt"
+
+// Invariant identifies a structural property that a valid tree maintains.
+type Invariant int
+
+const (
+	// Every node's parent pointer agrees with its parent's children.
+	ParentPointers Invariant = iota
+	// Keys in the left subtree of a node are smaller, and keys in the right subtree are larger.
+	SortOrder
+	// Every node's subtree size counts the nodes in its subtree.
+	SubtreeSize
+	// The root is black.
+	RootColor
+	// A red node has no red child.
+	RedRed
+	// All paths from a node down to its NIL leaves have the same number of black nodes.
+	BlackHeight
+	// Every node's augmented data, such as an interval tree's maximum endpoint, agrees with its subtree.
+	Augmentation
+)
+
+func (i Invariant) String() string {
+	switch i {
+	case ParentPointers:
+		return "parent pointers"
+	case SortOrder:
+		return "sort order"
+	case SubtreeSize:
+		return "subtree size"
+	case RootColor:
+		return "root color"
+	case RedRed:
+		return "red-red"
+	case BlackHeight:
+		return "black height"
+	case Augmentation:
+		return "augmentation"
+	}
+	return fmt.Sprintf("Invariant(%d)", int(i))
+}
+
+// InvariantError is returned by CheckInvariants, and describes the first violation found.
+type InvariantError struct {
+	Invariant Invariant
+
+	// Key of the node at which the violation was found.
+	Node any
+
+	// The offending values. What they are depends on the invariant:
+	// ParentPointers - the key of the child, and the key its parent pointer refers to (nil for none).
+	// SortOrder - the key of the out of order node in the subtree.
+	// SubtreeSize - the recorded size, and the actual number of nodes.
+	// RootColor - none.
+	// RedRed - the key of the red child.
+	// BlackHeight - the black heights of the left and right subtrees.
+	// Augmentation - the recorded data, and the data recomputed from the subtree.
+	Values []any
+
+	msg string
+}
+
+func (e *InvariantError) Error() string {
+	return fmt.Sprintf("%v invariant violated at node %v: %s", e.Invariant, e.Node, e.msg)
+}
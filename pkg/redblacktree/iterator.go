@@ -0,0 +1,108 @@
+package redblacktree
+
+// Returns the in-order neighbour of the node in the given direction.
+// Direction 1 gives the successor, and direction 0 gives the predecessor.
+func (nd *node[K, V]) step(dir int) *node[K, V] {
+
+	// Neighbour is the extreme node of the subtree in that direction.
+	if nd.children[dir] != nil {
+		return nd.children[dir].extreme(1 - dir)
+	}
+
+	// Otherwise, it's the first ancestor that we reach from the opposite direction.
+	for nd.parent != nil && nd.getChildID() == dir {
+		nd = nd.parent
+	}
+	return nd.parent
+}
+
+// Returns the left most (dir = 0) or right most (dir = 1) node of the subtree.
+func (nd *node[K, V]) extreme(dir int) *node[K, V] {
+	if nd == nil {
+		return nil
+	}
+
+	for nd.children[dir] != nil {
+		nd = nd.children[dir]
+	}
+	return nd
+}
+
+// Iterator is a cursor over the values of a Tree, in sorted order.
+//
+// Each step takes O(1) amortised time. An iterator is invalidated
+// by any modification of the tree.
+type Iterator[T any] struct {
+	nd *node[T, struct{}]
+}
+
+// Reports whether the iterator points to a value.
+func (it *Iterator[T]) Valid() bool {
+	return it.nd != nil
+}
+
+// Returns the value the iterator points to. Only defined for valid iterators.
+func (it *Iterator[T]) Value() T {
+	return it.nd.data
+}
+
+// Moves to the next larger value. Only defined for valid iterators.
+func (it *Iterator[T]) Next() {
+	it.nd = it.nd.step(1)
+}
+
+// Moves to the next smaller value. Only defined for valid iterators.
+func (it *Iterator[T]) Prev() {
+	it.nd = it.nd.step(0)
+}
+
+// Returns an iterator pointing to the smallest value.
+func (t *Tree[T]) First() *Iterator[T] {
+	return &Iterator[T]{nd: t.root.extreme(0)}
+}
+
+// Returns an iterator pointing to the largest value.
+func (t *Tree[T]) Last() *Iterator[T] {
+	return &Iterator[T]{nd: t.root.extreme(1)}
+}
+
+// Returns an iterator pointing to the smallest value greater than or equal to pivot.
+func (t *Tree[T]) Seek(pivot T) *Iterator[T] {
+	return &Iterator[T]{nd: t.ceilingNode(pivot)}
+}
+
+// Calls fn for every value in ascending order, until fn returns false.
+func (t *Tree[T]) Ascend(fn func(obj T) bool) {
+	for it := t.First(); it.Valid(); it.Next() {
+		if !fn(it.Value()) {
+			return
+		}
+	}
+}
+
+// Calls fn for every value in descending order, until fn returns false.
+func (t *Tree[T]) Descend(fn func(obj T) bool) {
+	for it := t.Last(); it.Valid(); it.Prev() {
+		if !fn(it.Value()) {
+			return
+		}
+	}
+}
+
+// Calls fn for every value in [lo, hi) in ascending order, until fn returns false.
+func (t *Tree[T]) AscendRange(lo T, hi T, fn func(obj T) bool) {
+	for it := t.Seek(lo); it.Valid(); it.Next() {
+		if t.compare(it.Value(), hi) >= 0 || !fn(it.Value()) {
+			return
+		}
+	}
+}
+
+// Calls fn for every value greater than or equal to pivot in ascending order, until fn returns false.
+func (t *Tree[T]) AscendGreaterOrEqual(pivot T, fn func(obj T) bool) {
+	for it := t.Seek(pivot); it.Valid(); it.Next() {
+		if !fn(it.Value()) {
+			return
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package redblacktree
+
+import "golang.org/x/exp/constraints"
+
+// Map is an ordered map from keys to values.
+//
+// The zero value is an empty map ordering keys by their natural order.
+type Map[K constraints.Ordered, V any] struct {
+	tree[K, V]
+}
+
+// Associates value with key, replacing any value already associated with it.
+func (m *Map[K, V]) Put(key K, value V) {
+	nd, _ := m.insert(key)
+	nd.value = value
+}
+
+// Returns the value associated with key, and whether the key was found.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	_, nd := m.findParentAndNode(key)
+	if nd == nil {
+		var nilVal V
+		return nilVal, false
+	}
+	return nd.value, true
+}
@@ -0,0 +1,226 @@
+package redblacktree
+
+import "fmt"
+
+// What a multiset keeps at each node, besides the value.
+type multiplicity struct {
+	// Number of occurrences of the node's value.
+	count int
+
+	// Number of occurrences of all values in the node's subtree.
+	total int
+}
+
+// Multiset is an ordered collection of values that keeps duplicates. Each distinct value
+// is held once, along with its number of occurrences, and every node is augmented with
+// the total number of occurrences in its subtree so that order statistics count duplicates.
+//
+// The zero value is an empty multiset ordering values by their natural order,
+// and is only usable when T is an ordered type.
+type Multiset[T any] struct {
+	t tree[T, multiplicity]
+}
+
+// Creates an empty multiset ordering values using cmp.
+func NewMultisetWithComparator[T any](cmp func(a, b T) int) *Multiset[T] {
+	ms := &Multiset[T]{}
+	ms.t.cmp = cmp
+	return ms
+}
+
+func (ms *Multiset[T]) core() *tree[T, multiplicity] {
+	if ms.t.augment == nil {
+		ms.t.augment = augmentTotal[T]
+	}
+	return &ms.t
+}
+
+func augmentTotal[T any](nd *node[T, multiplicity]) {
+	nd.value.total = expectedTotal(nd)
+}
+
+func expectedTotal[T any](nd *node[T, multiplicity]) int {
+	total := nd.value.count
+	for _, child := range nd.children {
+		if child != nil {
+			total += child.value.total
+		}
+	}
+	return total
+}
+
+func totalOf[T any](nd *node[T, multiplicity]) int {
+	if nd == nil {
+		return 0
+	}
+	return nd.value.total
+}
+
+// Adds an occurrence of obj.
+func (ms *Multiset[T]) Insert(obj T) {
+	t := ms.core()
+	nd, _ := t.insert(obj)
+	nd.value.count++
+	t.augmentUp(nd)
+}
+
+// Removes one occurrence of obj. Returns false if obj was not in the multiset.
+func (ms *Multiset[T]) Remove(obj T) bool {
+	t := ms.core()
+	_, nd := t.findParentAndNode(obj)
+	if nd == nil {
+		return false
+	}
+
+	if nd.value.count == 1 {
+		return t.Delete(obj)
+	}
+
+	nd.value.count--
+	t.augmentUp(nd)
+	return true
+}
+
+// Removes all occurrences of obj, and returns how many there were.
+func (ms *Multiset[T]) RemoveAll(obj T) int {
+	n := ms.Count(obj)
+	if n > 0 {
+		ms.core().Delete(obj)
+	}
+	return n
+}
+
+// Returns the number of occurrences of obj.
+func (ms *Multiset[T]) Count(obj T) int {
+	_, nd := ms.t.findParentAndNode(obj)
+	if nd == nil {
+		return 0
+	}
+	return nd.value.count
+}
+
+func (ms *Multiset[T]) Contains(obj T) bool {
+	return ms.t.Contains(obj)
+}
+
+// Returns the number of values, counting duplicates.
+func (ms *Multiset[T]) Len() int {
+	return totalOf(ms.t.root)
+}
+
+// Returns the number of distinct values.
+func (ms *Multiset[T]) Distinct() int {
+	return ms.t.Len()
+}
+
+// Returns the smallest value, and false if the multiset is empty.
+func (ms *Multiset[T]) Min() (T, bool) {
+	return dataOf(ms.t.root.extreme(0))
+}
+
+// Returns the largest value, and false if the multiset is empty.
+func (ms *Multiset[T]) Max() (T, bool) {
+	return dataOf(ms.t.root.extreme(1))
+}
+
+// Returns the number of values smaller than obj, counting duplicates.
+func (ms *Multiset[T]) Rank(obj T) int {
+
+	r := 0
+	nd := ms.t.root
+	for nd != nil {
+		if ms.t.compare(obj, nd.data) <= 0 {
+			nd = nd.children[0]
+		} else {
+			r += totalOf(nd.children[0]) + nd.value.count
+			nd = nd.children[1]
+		}
+	}
+	return r
+}
+
+// Returns the k-th smallest value counting duplicates, counting from 0,
+// and false if k is out of range. Select(Len()/2) is the median.
+func (ms *Multiset[T]) Select(k int) (T, bool) {
+
+	if k < 0 || k >= ms.Len() {
+		var nilVal T
+		return nilVal, false
+	}
+
+	nd := ms.t.root
+	for {
+		ltotal := totalOf(nd.children[0])
+		if k < ltotal {
+			nd = nd.children[0]
+		} else if k >= ltotal+nd.value.count {
+			k -= ltotal + nd.value.count
+			nd = nd.children[1]
+		} else {
+			return nd.data, true
+		}
+	}
+}
+
+// Calls fn for every value in ascending order, once per occurrence, until fn returns false.
+func (ms *Multiset[T]) Ascend(fn func(obj T) bool) {
+	for nd := ms.t.root.extreme(0); nd != nil; nd = nd.step(1) {
+		for i := 0; i < nd.value.count; i++ {
+			if !fn(nd.data) {
+				return
+			}
+		}
+	}
+}
+
+// Calls fn for every value in descending order, once per occurrence, until fn returns false.
+func (ms *Multiset[T]) Descend(fn func(obj T) bool) {
+	for nd := ms.t.root.extreme(1); nd != nil; nd = nd.step(0) {
+		for i := 0; i < nd.value.count; i++ {
+			if !fn(nd.data) {
+				return
+			}
+		}
+	}
+}
+
+func validateTotalInvariant[T any](nd *node[T, multiplicity]) error {
+
+	if nd == nil {
+		return nil
+	}
+
+	for _, child := range nd.children {
+		err := validateTotalInvariant(child)
+		if err != nil {
+			return err
+		}
+	}
+
+	expected := expectedTotal(nd)
+	if nd.value.count < 1 || nd.value.total != expected {
+		return &InvariantError{
+			Invariant: Augmentation,
+			Node:      nd.data,
+			Values:    []any{nd.value.total, expected},
+			msg:       fmt.Sprintf("node %v with count %d records total %d, but its subtree has total %d", nd.data, nd.value.count, nd.value.total, expected),
+		}
+	}
+
+	return nil
+}
+
+// Validates the structure of the multiset, along with the counts recorded at each node.
+func (ms *Multiset[T]) CheckInvariants() error {
+
+	err := ms.core().CheckInvariants()
+	if err != nil {
+		return err
+	}
+
+	return validateTotalInvariant(ms.t.root)
+}
+
+func (ms *Multiset[T]) Print() {
+	ms.t.Print()
+}
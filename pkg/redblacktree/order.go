@@ -0,0 +1,117 @@
+package redblacktree
+
+// Returns the node with the smallest key greater than or equal to obj, if any.
+func (t *tree[K, V]) ceilingNode(obj K) *node[K, V] {
+
+	var ceil *node[K, V]
+	nd := t.root
+	for nd != nil {
+		c := t.compare(obj, nd.data)
+		if c == 0 {
+			return nd
+		}
+		if c < 0 {
+			ceil = nd
+			nd = nd.children[0]
+		} else {
+			nd = nd.children[1]
+		}
+	}
+	return ceil
+}
+
+// Returns the node with the largest key less than or equal to obj, if any.
+func (t *tree[K, V]) floorNode(obj K) *node[K, V] {
+
+	var floor *node[K, V]
+	nd := t.root
+	for nd != nil {
+		c := t.compare(obj, nd.data)
+		if c == 0 {
+			return nd
+		}
+		if c > 0 {
+			floor = nd
+			nd = nd.children[1]
+		} else {
+			nd = nd.children[0]
+		}
+	}
+	return floor
+}
+
+// Returns the number of keys smaller than obj.
+func (t *tree[K, V]) rank(obj K) int {
+
+	r := 0
+	nd := t.root
+	for nd != nil {
+		c := t.compare(obj, nd.data)
+		if c <= 0 {
+			nd = nd.children[0]
+		} else {
+			r += nd.children[0].getSize() + 1
+			nd = nd.children[1]
+		}
+	}
+	return r
+}
+
+// Returns the node with the k-th smallest key, counting from 0, if any.
+func (t *tree[K, V]) selectNode(k int) *node[K, V] {
+
+	if k < 0 || k >= t.root.getSize() {
+		return nil
+	}
+
+	nd := t.root
+	for {
+		lsize := nd.children[0].getSize()
+		if k < lsize {
+			nd = nd.children[0]
+		} else if k > lsize {
+			k -= lsize + 1
+			nd = nd.children[1]
+		} else {
+			return nd
+		}
+	}
+}
+
+func dataOf[K any, V any](nd *node[K, V]) (K, bool) {
+	if nd == nil {
+		var nilVal K
+		return nilVal, false
+	}
+	return nd.data, true
+}
+
+// Returns the smallest value, and false if the tree is empty.
+func (t *Tree[T]) Min() (T, bool) {
+	return dataOf(t.root.extreme(0))
+}
+
+// Returns the largest value, and false if the tree is empty.
+func (t *Tree[T]) Max() (T, bool) {
+	return dataOf(t.root.extreme(1))
+}
+
+// Returns the largest value less than or equal to obj, and false if there is none.
+func (t *Tree[T]) Floor(obj T) (T, bool) {
+	return dataOf(t.floorNode(obj))
+}
+
+// Returns the smallest value greater than or equal to obj, and false if there is none.
+func (t *Tree[T]) Ceiling(obj T) (T, bool) {
+	return dataOf(t.ceilingNode(obj))
+}
+
+// Returns the number of values smaller than obj.
+func (t *Tree[T]) Rank(obj T) int {
+	return t.rank(obj)
+}
+
+// Returns the k-th smallest value, counting from 0, and false if k is out of range.
+func (t *Tree[T]) Select(k int) (T, bool) {
+	return dataOf(t.selectNode(k))
+}
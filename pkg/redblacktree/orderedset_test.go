@@ -0,0 +1,26 @@
+package redblacktree
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/grsubramanian/go-playground/pkg/orderedset"
+	"github.com/grsubramanian/go-playground/pkg/orderedset/orderedsettest"
+)
+
+var (
+	_ orderedset.OrderedSet[int] = &Tree[int]{}
+	_ orderedset.Balanced        = &Tree[int]{}
+)
+
+func TestOrderedSetConformance(t *testing.T) {
+	orderedsettest.Run(t, func() orderedset.OrderedSet[int] { return &Tree[int]{} }, func(i int) int { return i }, 1000)
+}
+
+func TestOrderedSetConformanceWithComparator(t *testing.T) {
+	// Strings in reverse order, so that increasing keys are decreasing strings.
+	newSet := func() orderedset.OrderedSet[string] {
+		return NewWithComparator(func(a, b string) int { return compareOrdered(b, a) })
+	}
+	orderedsettest.Run(t, newSet, func(i int) string { return fmt.Sprintf("%06d", 1000000-i) }, 1000)
+}
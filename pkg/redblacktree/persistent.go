@@ -0,0 +1,315 @@
+package redblacktree
+
+// PersistentTree is an immutable ordered set of values. Insert and Delete
+// leave the tree untouched, and return a new version that shares all
+// the unchanged subtrees with it. Only the O(log n) nodes along the
+// modified path are copied.
+//
+// Since nodes are shared between versions, they have no parent pointers.
+// Versions are safe for concurrent use by multiple goroutines.
+//
+// The zero value is an empty tree ordering values by their natural order,
+// and is only usable when T is an ordered type.
+type PersistentTree[T any] struct {
+	t tree[T, struct{}]
+}
+
+// Creates an empty persistent tree ordering values using cmp.
+func NewPersistentWithComparator[T any](cmp func(a, b T) int) *PersistentTree[T] {
+	return &PersistentTree[T]{t: tree[T, struct{}]{cmp: cmp}}
+}
+
+// Returns a copy of the node that can be modified without affecting other versions.
+func (nd *node[K, V]) copy() *node[K, V] {
+	cpy := *nd
+	return &cpy
+}
+
+func isRed[K any, V any](nd *node[K, V]) bool {
+	return nd != nil && nd.color == Red
+}
+
+// Returns a version in which the tree has the given root. The comparator is
+// resolved before being handed on, so that reads never need to write to a version.
+func (pt *PersistentTree[T]) withRoot(root *node[T, struct{}]) *PersistentTree[T] {
+	cmp := pt.t.cmp
+	if cmp == nil {
+		cmp = naturalOrder[T]()
+	}
+	return &PersistentTree[T]{t: tree[T, struct{}]{root: root, cmp: cmp}}
+}
+
+// Returns a version of the tree that also contains obj.
+func (pt *PersistentTree[T]) Insert(obj T) *PersistentTree[T] {
+
+	next := pt.withRoot(pt.t.root)
+
+	// No op. Object already exists.
+	if next.t.Contains(obj) {
+		return pt
+	}
+
+	root := next.insert(next.t.root, obj)
+
+	// It's always safe to make the root node black.
+	root.color = Black
+	next.t.root = root
+	return next
+}
+
+// Returns a copy of the subtree with obj inserted. The returned root is always a fresh copy.
+func (pt *PersistentTree[T]) insert(nd *node[T, struct{}], obj T) *node[T, struct{}] {
+
+	if nd == nil {
+		return &node[T, struct{}]{
+			color: Red,
+			size:  1,
+			data:  obj,
+		}
+	}
+
+	cpy := nd.copy()
+	cid := 0
+	if pt.t.compare(obj, nd.data) > 0 {
+		cid = 1
+	}
+	cpy.children[cid] = pt.insert(nd.children[cid], obj)
+	cpy.size++
+
+	return cpy.fixDoubleRed(cid)
+}
+
+// The node is a fresh copy whose cid'th child (also a fresh copy) was just modified.
+// Resolves a double red problem below the node, if there is one, and returns the new
+// root of the subtree.
+func (nd *node[K, V]) fixDoubleRed(cid int) *node[K, V] {
+
+	// Only a black node with a red child can have a double red problem below it.
+	// A red node's parent will deal with it.
+	if nd.color != Black {
+		return nd
+	}
+
+	c := nd.children[cid]
+	if !isRed(c) {
+		return nd
+	}
+
+	// Find the red grandchild, if any. It's on the modified path, so it's also a fresh copy.
+	var gc *node[K, V]
+	var gcid int
+	for _, i := range []int{cid, 1 - cid} {
+		if isRed(c.children[i]) {
+			gc, gcid = c.children[i], i
+			break
+		}
+	}
+	if gc == nil {
+		return nd
+	}
+
+	// Restructure nd, c and gc so that the middle one of them becomes the root of the
+	// subtree, colored red, with the other two as its black children.
+	var top *node[K, V]
+	if gcid == cid {
+		// nd, c and gc are in a straight line. c moves up.
+		nd.children[cid] = c.children[1-cid]
+		c.children[1-cid] = nd
+		top = c
+	} else {
+		// nd, c and gc form a triangle. gc moves up by two levels.
+		c.children[gcid] = gc.children[cid]
+		nd.children[cid] = gc.children[gcid]
+		gc.children[cid] = c
+		gc.children[gcid] = nd
+		top = gc
+	}
+
+	for _, child := range top.children {
+		child.color = Black
+		child.updateSize()
+	}
+	top.color = Red
+	top.updateSize()
+	return top
+}
+
+// Returns a version of the tree without obj.
+func (pt *PersistentTree[T]) Delete(obj T) *PersistentTree[T] {
+
+	next := pt.withRoot(pt.t.root)
+
+	// No op. Object does not exist.
+	if !next.t.Contains(obj) {
+		return pt
+	}
+
+	root, _ := next.delete(next.t.root, obj)
+	if root != nil && root.color != Black {
+		root = root.copy()
+		root.color = Black
+	}
+	next.t.root = root
+	return next
+}
+
+// Returns a copy of the subtree with obj deleted, and whether the black height of the subtree
+// went down by one. obj must exist in the subtree.
+func (pt *PersistentTree[T]) delete(nd *node[T, struct{}], obj T) (*node[T, struct{}], bool) {
+
+	c := pt.t.compare(obj, nd.data)
+	if c != 0 {
+		cid := 0
+		if c > 0 {
+			cid = 1
+		}
+
+		child, shorter := pt.delete(nd.children[cid], obj)
+		cpy := nd.copy()
+		cpy.children[cid] = child
+		cpy.size--
+		if !shorter {
+			return cpy, false
+		}
+		return cpy.fixDoubleBlack(cid)
+	}
+
+	if nd.children[0] != nil && nd.children[1] != nil {
+		// Node has two children. Replace its data with the in-order successor's
+		// and delete the successor from the right subtree instead.
+		succ := nd.children[1].extreme(0)
+		child, shorter := pt.delete(nd.children[1], succ.data)
+		cpy := nd.copy()
+		cpy.data = succ.data
+		cpy.value = succ.value
+		cpy.children[1] = child
+		cpy.size--
+		if !shorter {
+			return cpy, false
+		}
+		return cpy.fixDoubleBlack(1)
+	}
+
+	child := nd.children[0]
+	if child == nil {
+		child = nd.children[1]
+	}
+
+	// A red node has no single child, so it's a leaf that can simply be removed.
+	if nd.color == Red {
+		return nil, false
+	}
+
+	// A black node with a single child has a red child. Replace the node with the child,
+	// and make the child black to restore the black depth along its path.
+	if child != nil {
+		cpy := child.copy()
+		cpy.color = Black
+		return cpy, false
+	}
+
+	// Removing a black leaf shortens the path.
+	return nil, true
+}
+
+// The node is a fresh copy whose cid'th subtree has a black height one less than its other subtree.
+// Restores the black height through recolors and rotations, and returns the new root of the subtree
+// along with whether the black height of the whole subtree went down by one.
+func (nd *node[K, V]) fixDoubleBlack(cid int) (*node[K, V], bool) {
+
+	// The sibling always exists, since its subtree has a black height of at least 2.
+	sib := nd.children[1-cid].copy()
+	nd.children[1-cid] = sib
+
+	if sib.color == Red {
+		// Sibling is red, so node is black.
+		// Rotate the sibling above the node so that the shorter subtree gets a black sibling.
+		nd.children[1-cid] = sib.children[cid]
+		sib.children[cid] = nd
+		sib.color = Black
+		nd.color = Red
+
+		// Node is red now, so fixing it never shortens its subtree further.
+		fixed, _ := nd.fixDoubleBlack(cid)
+		sib.children[cid] = fixed
+		sib.updateSize()
+		return sib, false
+	}
+
+	// The nephew closer to the shorter subtree, and the one further away from it.
+	near := sib.children[cid]
+	far := sib.children[1-cid]
+
+	if !isRed(near) && !isRed(far) {
+		// Sibling and both nephews are black.
+		// Make the sibling red, which shortens its subtree too.
+		sib.color = Red
+
+		nd.updateSize()
+
+		// If the node is red, making it black makes up for it. Otherwise the whole subtree is shorter.
+		if nd.color == Red {
+			nd.color = Black
+			return nd, false
+		}
+		return nd, true
+	}
+
+	var top *node[K, V]
+	if isRed(far) {
+		// Rotate the sibling above the node, and recolor to make up for the black.
+		far = far.copy()
+		sib.children[1-cid] = far
+		nd.children[1-cid] = near
+		sib.children[cid] = nd
+		far.color = Black
+		top = sib
+	} else {
+		// Near nephew is red, far nephew is black.
+		// Rotate the near nephew above both the sibling and the node.
+		near = near.copy()
+		nd.children[1-cid] = near.children[cid]
+		sib.children[cid] = near.children[1-cid]
+		near.children[cid] = nd
+		near.children[1-cid] = sib
+		top = near
+	}
+
+	top.color = nd.color
+	nd.color = Black
+	nd.updateSize()
+	top.children[1-cid].updateSize()
+	top.updateSize()
+	return top, false
+}
+
+func (pt *PersistentTree[T]) Contains(obj T) bool {
+	return pt.t.Contains(obj)
+}
+
+func (pt *PersistentTree[T]) Len() int {
+	return pt.t.Len()
+}
+
+// Calls fn for every value in ascending order, until fn returns false.
+func (pt *PersistentTree[T]) Ascend(fn func(obj T) bool) {
+	pt.t.root.ascend(fn)
+}
+
+// Walks the subtree in order without parent pointers. Returns false if fn stopped the walk.
+func (nd *node[K, V]) ascend(fn func(obj K) bool) bool {
+	if nd == nil {
+		return true
+	}
+	return nd.children[0].ascend(fn) && fn(nd.data) && nd.children[1].ascend(fn)
+}
+
+func (pt *PersistentTree[T]) Print() {
+	pt.t.Print()
+}
+
+// Validates the structure of this version of the tree, with the same checks as Tree.CheckInvariants
+// except for parent pointers.
+func (pt *PersistentTree[T]) CheckInvariants() error {
+	return pt.t.checkInvariants(false)
+}
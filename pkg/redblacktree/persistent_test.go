@@ -0,0 +1,116 @@
+package redblacktree
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// A version of the tree, along with the values it's expected to hold.
+type persistentVersion struct {
+	pt       *PersistentTree[int]
+	expected []int
+}
+
+func checkVersion(t *testing.T, i int, v persistentVersion) {
+	t.Helper()
+
+	if err := v.pt.CheckInvariants(); err != nil {
+		t.Fatalf("version %d: %v", i, err)
+	}
+
+	var got []int
+	v.pt.Ascend(func(val int) bool {
+		got = append(got, val)
+		return true
+	})
+
+	if len(got) != len(v.expected) || v.pt.Len() != len(v.expected) {
+		t.Fatalf("version %d holds %v, expected %v", i, got, v.expected)
+	}
+	for j := range got {
+		if got[j] != v.expected[j] {
+			t.Fatalf("version %d holds %v, expected %v", i, got, v.expected)
+		}
+	}
+}
+
+// Every version, old and new, should still hold exactly what it held when it was created.
+func TestPersistentOldVersionsUnchanged(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+	versions := []persistentVersion{{pt: &PersistentTree[int]{}}}
+
+	for i := 0; i < 300; i++ {
+		prev := versions[len(versions)-1]
+		val := r.Intn(50)
+
+		pos := sort.SearchInts(prev.expected, val)
+		exists := pos < len(prev.expected) && prev.expected[pos] == val
+		expected := append([]int{}, prev.expected...)
+
+		var next *PersistentTree[int]
+		if r.Intn(3) == 0 {
+			next = prev.pt.Delete(val)
+			if exists {
+				expected = append(expected[:pos], expected[pos+1:]...)
+			}
+		} else {
+			next = prev.pt.Insert(val)
+			if !exists {
+				expected = append(expected[:pos], append([]int{val}, expected[pos:]...)...)
+			}
+		}
+		versions = append(versions, persistentVersion{pt: next, expected: expected})
+
+		for j, v := range versions {
+			checkVersion(t, j, v)
+		}
+	}
+}
+
+// Returns a black leaf whose sibling is red, if there's one in the subtree.
+func blackLeafWithRedSibling(nd *node[int, struct{}]) (int, bool) {
+	if nd == nil {
+		return 0, false
+	}
+	for cid, child := range nd.children {
+		if child != nil && child.color == Black && child.children == [2]*node[int, struct{}]{} && isRed(nd.children[1-cid]) {
+			return child.data, true
+		}
+	}
+	for _, child := range nd.children {
+		if val, ok := blackLeafWithRedSibling(child); ok {
+			return val, true
+		}
+	}
+	return 0, false
+}
+
+// Deleting a black leaf whose sibling is red takes the rotation in fixDoubleBlack that
+// random workloads rarely reach.
+func TestPersistentDeleteWithRedSibling(t *testing.T) {
+
+	old := &PersistentTree[int]{}
+	var expected []int
+	for i := 1; i <= 6; i++ {
+		old = old.Insert(i)
+		expected = append(expected, i)
+	}
+
+	val, ok := blackLeafWithRedSibling(old.t.root)
+	if !ok {
+		t.Fatalf("no black leaf with a red sibling to delete")
+	}
+
+	next := old.Delete(val)
+
+	var remaining []int
+	for _, v := range expected {
+		if v != val {
+			remaining = append(remaining, v)
+		}
+	}
+	checkVersion(t, 0, persistentVersion{pt: old, expected: expected})
+	checkVersion(t, 1, persistentVersion{pt: next, expected: remaining})
+}
@@ -2,29 +2,38 @@ package redblacktree
 
 import (
 	"fmt"
-	"log"
 	"strings"
-
-	"golang.org/x/exp/constraints"
 )
 
-type color int
+// Color of a node.
+type Color int
 
 const (
-	red color = iota
-	black
+	Red Color = iota
+	Black
 )
 
-type node[T constraints.Ordered] struct {
-	parent   *node[T]
-	children [2]*node[T]
+func (c Color) String() string {
+	if c == Black {
+		return "black"
+	}
+	return "red"
+}
+
+type node[K any, V any] struct {
+	parent   *node[K, V]
+	children [2]*node[K, V]
+
+	color Color
 
-	color color
+	// Number of nodes in the subtree rooted at this node.
+	size int
 
-	data T
+	data  K
+	value V
 }
 
-func (nd *node[T]) getChildID() int {
+func (nd *node[K, V]) getChildID() int {
 	// Only defined for non-nil nodes.
 
 	p := nd.parent
@@ -39,7 +48,25 @@ func (nd *node[T]) getChildID() int {
 	return 1
 }
 
-func (nd *node[T]) sibling() *node[T] {
+func (nd *node[K, V]) getSize() int {
+	if nd == nil {
+		return 0
+	}
+	return nd.size
+}
+
+func (nd *node[K, V]) updateSize() {
+	nd.size = 1 + nd.children[0].getSize() + nd.children[1].getSize()
+}
+
+// Adds delta to the sizes of the node and all its ancestors.
+func (nd *node[K, V]) adjustSizes(delta int) {
+	for ; nd != nil; nd = nd.parent {
+		nd.size += delta
+	}
+}
+
+func (nd *node[K, V]) sibling() *node[K, V] {
 	// Only defined for non-nil nodes.
 
 	p := nd.parent
@@ -51,36 +78,46 @@ func (nd *node[T]) sibling() *node[T] {
 	return p.children[1-nd.getChildID()]
 }
 
-func (nd *node[T]) validateSortInvariant() (T, T, error) {
+func (nd *node[K, V]) validateSortInvariant(cmp func(a, b K) int) (K, K, error) {
 
 	maxVal := nd.data
 	minVal := nd.data
 
 	if nd.children[0] != nil {
-		maxValLChild, minValLChild, err := nd.children[0].validateSortInvariant()
+		maxValLChild, minValLChild, err := nd.children[0].validateSortInvariant(cmp)
 		if err != nil {
-			var nilVal T
+			var nilVal K
 			return nilVal, nilVal, err
 		}
 
-		if nd.data < maxValLChild {
-			var nilVal T
-			return nilVal, nilVal, fmt.Errorf("node %v has smaller value than some node %v in its left subtree", nd.data, maxValLChild)
+		if cmp(nd.data, maxValLChild) < 0 {
+			var nilVal K
+			return nilVal, nilVal, &InvariantError{
+				Invariant: SortOrder,
+				Node:      nd.data,
+				Values:    []any{maxValLChild},
+				msg:       fmt.Sprintf("node %v has smaller value than some node %v in its left subtree", nd.data, maxValLChild),
+			}
 		}
 
 		minVal = minValLChild
 	}
 
 	if nd.children[1] != nil {
-		maxValRChild, minValRChild, err := nd.children[1].validateSortInvariant()
+		maxValRChild, minValRChild, err := nd.children[1].validateSortInvariant(cmp)
 		if err != nil {
-			var nilVal T
+			var nilVal K
 			return nilVal, nilVal, err
 		}
 
-		if nd.data > minValRChild {
-			var nilVal T
-			return nilVal, nilVal, fmt.Errorf("node %v has larger value than some node %v in its right subtree", nd.data, minValRChild)
+		if cmp(nd.data, minValRChild) > 0 {
+			var nilVal K
+			return nilVal, nilVal, &InvariantError{
+				Invariant: SortOrder,
+				Node:      nd.data,
+				Values:    []any{minValRChild},
+				msg:       fmt.Sprintf("node %v has larger value than some node %v in its right subtree", nd.data, minValRChild),
+			}
 		}
 
 		maxVal = maxValRChild
@@ -89,57 +126,124 @@ func (nd *node[T]) validateSortInvariant() (T, T, error) {
 	return maxVal, minVal, nil
 }
 
-// returns max black depth, min black depth, error in case of black invar
-func (nd *node[T]) validateSubtreeRespectsBlackColorInvariant() (int, int, error) {
+// Returns the black height of the subtree, counting the NIL leaves.
+func (nd *node[K, V]) validateSubtreeRespectsBlackColorInvariant() (int, error) {
 	if nd == nil {
-		return 1, 1, nil
+		return 1, nil
 	}
 
-	maxBDLChild, minBDLChild, err := nd.children[0].validateSubtreeRespectsBlackColorInvariant()
+	bhLChild, err := nd.children[0].validateSubtreeRespectsBlackColorInvariant()
 	if err != nil {
-		return 0, 0, err
+		return 0, err
 	}
 
-	maxBDRChild, minBDRChild, err := nd.children[1].validateSubtreeRespectsBlackColorInvariant()
+	bhRChild, err := nd.children[1].validateSubtreeRespectsBlackColorInvariant()
 	if err != nil {
-		return 0, 0, err
+		return 0, err
+	}
+
+	if bhLChild != bhRChild {
+		return 0, &InvariantError{
+			Invariant: BlackHeight,
+			Node:      nd.data,
+			Values:    []any{bhLChild, bhRChild},
+			msg:       fmt.Sprintf("not all paths under node %v have the same black depth, left depth = %d, right depth = %d", nd.data, bhLChild, bhRChild),
+		}
+	}
+
+	bh := bhLChild
+	if nd.color == Black {
+		bh += 1
+	}
+
+	return bh, nil
+}
+
+func (nd *node[K, V]) validateParentPointers() error {
+
+	if nd == nil {
+		return nil
 	}
 
-	maxBD := maxBDLChild
-	if maxBDRChild > maxBDLChild {
-		maxBD = maxBDRChild
+	for _, child := range nd.children {
+		if child == nil {
+			continue
+		}
+
+		if child.parent != nd {
+			var parentVal any
+			if child.parent != nil {
+				parentVal = child.parent.data
+			}
+			return &InvariantError{
+				Invariant: ParentPointers,
+				Node:      nd.data,
+				Values:    []any{child.data, parentVal},
+				msg:       fmt.Sprintf("child %v of node %v has parent pointer to %v", child.data, nd.data, parentVal),
+			}
+		}
+
+		err := child.validateParentPointers()
+		if err != nil {
+			return err
+		}
 	}
 
-	minBD := minBDLChild
-	if minBDRChild < minBDLChild {
-		minBD = minBDRChild
+	return nil
+}
+
+func (nd *node[K, V]) validateSizeInvariant() error {
+
+	if nd == nil {
+		return nil
 	}
 
-	if nd.color == black {
-		maxBD += 1
-		minBD += 1
+	err := nd.children[0].validateSizeInvariant()
+	if err != nil {
+		return err
+	}
+
+	err = nd.children[1].validateSizeInvariant()
+	if err != nil {
+		return err
 	}
 
-	if maxBD != minBD {
-		return 0, 0, fmt.Errorf("not all paths under node %v have the same black depth, max depth = %d, min depth = %d", maxBD, minBD)
+	expected := 1 + nd.children[0].getSize() + nd.children[1].getSize()
+	if nd.size != expected {
+		return &InvariantError{
+			Invariant: SubtreeSize,
+			Node:      nd.data,
+			Values:    []any{nd.size, expected},
+			msg:       fmt.Sprintf("node %v has size %d, but its subtree has %d nodes", nd.data, nd.size, expected),
+		}
 	}
 
-	return maxBD, minBD, nil
+	return nil
 }
 
-func (nd *node[T]) validateSubtreeRespectsRedColorInvariant() error {
+func (nd *node[K, V]) validateSubtreeRespectsRedColorInvariant() error {
 
 	if nd == nil {
 		return nil
 	}
 
-	if nd.color == red {
-		if nd.children[0] != nil && nd.children[0].color == red {
-			return fmt.Errorf("node %v and its child %v are both colored red", nd.data, nd.children[0].data)
+	if nd.color == Red {
+		if nd.children[0] != nil && nd.children[0].color == Red {
+			return &InvariantError{
+				Invariant: RedRed,
+				Node:      nd.data,
+				Values:    []any{nd.children[0].data},
+				msg:       fmt.Sprintf("node %v and its child %v are both colored red", nd.data, nd.children[0].data),
+			}
 		}
 
-		if nd.children[1] != nil && nd.children[1].color == red {
-			return fmt.Errorf("node %v and its child %v are both colored red", nd.data, nd.children[1].data)
+		if nd.children[1] != nil && nd.children[1].color == Red {
+			return &InvariantError{
+				Invariant: RedRed,
+				Node:      nd.data,
+				Values:    []any{nd.children[1].data},
+				msg:       fmt.Sprintf("node %v and its child %v are both colored red", nd.data, nd.children[1].data),
+			}
 		}
 	}
 
@@ -156,55 +260,103 @@ func (nd *node[T]) validateSubtreeRespectsRedColorInvariant() error {
 	return nil
 }
 
-type Tree[T constraints.Ordered] struct {
-	root *node[T]
+// tree holds the balancing logic shared by Tree and Map.
+type tree[K any, V any] struct {
+	root *node[K, V]
+
+	// Orders the keys. When nil, the keys are expected to be of an ordered type
+	// and are compared using their natural order.
+	cmp func(a, b K) int
+
+	// Observes changes to the structure of the tree, if set.
+	tracer Tracer[K]
+
+	// Recomputes any data that a node keeps about its subtree, other than its size,
+	// from the node and its children. If set, it's kept up to date through
+	// inserts, deletes and rotations.
+	augment func(nd *node[K, V])
+
+	// Number of rotations, recolors and rebalancing steps performed since the tree was created.
+	rotations      int
+	recolors       int
+	rebalanceSteps int
+}
+
+// Tree is an ordered set of values.
+//
+// The zero value is an empty tree ordering values by their natural order,
+// and is only usable when T is an ordered type. Use NewWithComparator for any other type.
+type Tree[T any] struct {
+	tree[T, struct{}]
+
+	// Whether marshalling preserves the exact shape and colors of the tree.
+	preserveShape bool
+}
+
+// Creates an empty tree ordering values using cmp, which must return
+// a negative number when a < b, a positive number when a > b, and zero otherwise.
+func NewWithComparator[T any](cmp func(a, b T) int) *Tree[T] {
+	t := &Tree[T]{}
+	t.cmp = cmp
+	return t
 }
 
 func (t *Tree[T]) Insert(obj T) {
-	log.Printf("Attempting to insert : %v", obj)
+	t.insert(obj)
+}
+
+// Returns the node holding obj, creating it if needed, and
+// whether the node was newly created.
+func (t *tree[K, V]) insert(obj K) (*node[K, V], bool) {
+	t.resolveComparator()
 
 	p, nd := t.findParentAndNode(obj)
 
 	// No op. Object already exists.
 	if nd != nil {
-		return
+		return nd, false
+	}
+
+	if t.tracer != nil {
+		t.tracer.OnInsert(obj)
 	}
 
 	// Create new node.
-	nd = &node[T]{
+	nd = &node[K, V]{
 		parent:   p,
-		children: [2]*node[T]{nil, nil},
+		children: [2]*node[K, V]{nil, nil},
+		size:     1,
 		data:     obj,
 	}
 
 	// Tree empty. Make the new node the root. And set its color to black.
 	if p == nil {
-		log.Printf("Inserting : %v as root", obj)
 		t.root = nd
-		nd.color = black
-		return
+		nd.color = Black
+		t.augmentUp(nd)
+		return nd, true
 	}
 
-	log.Printf("Inserting : %v", obj)
-
-	nd.color = red
+	nd.color = Red
 
-	if obj > p.data {
+	if t.compare(obj, p.data) > 0 {
 		p.children[1] = nd
 	} else {
 		// if obj < p.data
 		p.children[0] = nd
 	}
 
+	p.adjustSizes(1)
+	t.augmentUp(nd)
 	t.rebalance(nd)
+	return nd, true
 }
 
-func (t *Tree[T]) rebalance(nd *node[T]) {
-	log.Printf("Rebalancing : %v", nd.data)
+func (t *tree[K, V]) rebalance(nd *node[K, V]) {
+	t.rebalanceSteps++
 
 	// Only need to rebalance when node is red.
-	if nd.color != red {
-		log.Printf("Color is not red. No need to rebalance : %v", nd.data)
+	if nd.color != Red {
 		return
 	}
 
@@ -213,19 +365,15 @@ func (t *Tree[T]) rebalance(nd *node[T]) {
 	// Node is root. Just make it black.
 	// It's always safe to make the root node black.
 	if p == nil {
-		nd.color = black
-		log.Printf("%v is at root. Marking as black", nd.data)
+		t.recolor(nd, Black)
 		return
 	}
 
 	// Node is red, and parent is black. No op.
-	if p.color == black {
-		log.Printf("Parent's color is not red. No need to rebalance : %v", nd.data)
+	if p.color == Black {
 		return
 	}
 
-	log.Printf("Have a double red problem at %v. Need to change tree structure to meet the red-black invariants", nd.data)
-
 	// Otherwise, we have a double red problem. Need to change
 	// tree structure to get rid of the double red problem while
 	// maintaining the red-black invariants.
@@ -235,22 +383,20 @@ func (t *Tree[T]) rebalance(nd *node[T]) {
 	// If there is no grandparent, then parent is the root node. Just make it black.
 	// It's always safe to make the root node black.
 	if gp == nil {
-		log.Printf("No grandparent for %v. Marking parent as black", nd.data)
-		p.color = black
+		t.recolor(p, Black)
 		return
 	}
 
 	uncl := p.sibling()
 
-	if uncl == nil || uncl.color == black {
+	if uncl == nil || uncl.color == Black {
 		// nd and p are red.
 		// gp and uncl are black.
 
 		if nd.getChildID() == p.getChildID() {
 			// grandparent, parent and node are already in straight line.
-			p.color = black
-			gp.color = red
-			log.Printf("Marking parent and grandparent for %v as black and red respectively", nd.data)
+			t.recolor(p, Black)
+			t.recolor(gp, Red)
 			t.rotate(p)
 		} else {
 			// grandparent, parent and node form a triangle.
@@ -262,17 +408,15 @@ func (t *Tree[T]) rebalance(nd *node[T]) {
 	} else {
 		// nd, p and uncl are red.
 		// gp is black.
-		p.color = black
-		uncl.color = black
-		gp.color = red
-		log.Printf("Marking parent, uncle and grandparent for %v as black, black and red respectively", nd.data)
+		t.recolor(p, Black)
+		t.recolor(uncl, Black)
+		t.recolor(gp, Red)
 		t.rebalance(gp)
 	}
 }
 
-func (t *Tree[T]) rotate(nd *node[T]) {
+func (t *tree[K, V]) rotate(nd *node[K, V]) {
 	// We'll assume that nd is some node in the tree.
-	log.Printf("Rotating : %v", nd.data)
 
 	cid := nd.getChildID()
 	if cid == -1 {
@@ -280,6 +424,11 @@ func (t *Tree[T]) rotate(nd *node[T]) {
 		return
 	}
 
+	t.rotations++
+	if t.tracer != nil {
+		t.tracer.OnRotate(nd.data)
+	}
+
 	p := nd.parent
 	gp := p.parent
 
@@ -311,22 +460,218 @@ func (t *Tree[T]) rotate(nd *node[T]) {
 	if ndChild != nil {
 		ndChild.parent = p
 	}
+
+	// p is now a child of nd, so update its size first.
+	p.updateSize()
+	nd.updateSize()
+	if t.augment != nil {
+		t.augment(p)
+		t.augment(nd)
+	}
 }
 
-func (t *Tree[T]) Contains(obj T) bool {
+// Recomputes the augmented data of the node and all its ancestors.
+func (t *tree[K, V]) augmentUp(nd *node[K, V]) {
+	if t.augment == nil {
+		return
+	}
+
+	for ; nd != nil; nd = nd.parent {
+		t.augment(nd)
+	}
+}
+
+func (t *tree[K, V]) recolor(nd *node[K, V], c Color) {
+	if nd.color == c {
+		return
+	}
+
+	nd.color = c
+	t.recolors++
+	if t.tracer != nil {
+		t.tracer.OnRecolor(nd.data, c)
+	}
+}
+
+func (t *tree[K, V]) replace(nd *node[K, V], child *node[K, V]) {
+	// Puts child (possibly nil) in the place of nd in the tree.
+
+	p := nd.parent
+	switch nd.getChildID() {
+	case -1:
+		t.root = child
+	case 0:
+		p.children[0] = child
+	case 1:
+		p.children[1] = child
+	}
+
+	if child != nil {
+		child.parent = p
+	}
+	nd.parent = nil
+}
+
+func (t *tree[K, V]) Delete(obj K) bool {
+	t.resolveComparator()
+
+	_, nd := t.findParentAndNode(obj)
+
+	// No op. Object does not exist.
+	if nd == nil {
+		return false
+	}
+
+	if t.tracer != nil {
+		t.tracer.OnDelete(obj)
+	}
+
+	t.removeNode(nd)
+	return true
+}
+
+// Removes the data held by nd from the tree. The node that ends up detached
+// from the tree is nd itself, unless nd has two children.
+func (t *tree[K, V]) removeNode(nd *node[K, V]) {
+
+	// Node has two children. Move the in-order successor's data into the node
+	// and delete the successor instead, which has at most one child.
+	if nd.children[0] != nil && nd.children[1] != nil {
+		succ := nd.children[1]
+		for succ.children[0] != nil {
+			succ = succ.children[0]
+		}
+		nd.data = succ.data
+		nd.value = succ.value
+
+		// The successor still holds the same data, so the node's subtree is unchanged as a whole.
+		t.augmentUp(nd)
+		nd = succ
+	}
+
+	child := nd.children[0]
+	if child == nil {
+		child = nd.children[1]
+	}
+
+	if child != nil {
+		// A node with a single child must be black, and the child must be red.
+		// Replace the node with the child, and make the child black to restore
+		// the black depth along its path.
+		p := nd.parent
+		p.adjustSizes(-1)
+		t.replace(nd, child)
+		t.augmentUp(p)
+		t.recolor(child, Black)
+		return
+	}
+
+	// Node is a leaf. Removing a black leaf leaves a "double black" hole
+	// which needs to be fixed before the node is detached.
+	if nd.color == Black {
+		t.fixDoubleBlack(nd)
+	}
+
+	p := nd.parent
+	p.adjustSizes(-1)
+	t.replace(nd, nil)
+	t.augmentUp(p)
+}
+
+func (t *tree[K, V]) fixDoubleBlack(nd *node[K, V]) {
+	// nd carries an extra black. Push it up the tree, or absorb it
+	// through recolors and rotations.
+	t.rebalanceSteps++
+
+	p := nd.parent
+
+	// Node is root. The extra black can simply be dropped.
+	if p == nil {
+		return
+	}
+
+	cid := nd.getChildID()
+
+	// The sibling always exists, since the path through nd has a black depth of at least 2.
+	sib := nd.sibling()
+
+	if sib.color == Red {
+		// Sibling is red, so parent is black.
+		// Rotate the sibling above the parent so that nd gets a black sibling.
+		t.recolor(sib, Black)
+		t.recolor(p, Red)
+		t.rotate(sib)
+		sib = nd.sibling()
+	}
+
+	// The nephew closer to nd, and the one further away from it.
+	near := sib.children[cid]
+	far := sib.children[1-cid]
+
+	if (near == nil || near.color == Black) && (far == nil || far.color == Black) {
+		// Sibling and both nephews are black.
+		// Make the sibling red and move the extra black to the parent.
+		t.recolor(sib, Red)
+
+		if p.color == Red {
+			t.recolor(p, Black)
+			return
+		}
+
+		t.fixDoubleBlack(p)
+		return
+	}
+
+	if far == nil || far.color == Black {
+		// Near nephew is red, far nephew is black.
+		// Rotate the near nephew so that sibling and nephew get into a straight line.
+		t.recolor(near, Black)
+		t.recolor(sib, Red)
+		t.rotate(near)
+		far = sib
+		sib = near
+	}
+
+	// Far nephew is red.
+	// Rotate the sibling above the parent, and recolor to absorb the extra black.
+	t.recolor(sib, p.color)
+	t.recolor(p, Black)
+	t.recolor(far, Black)
+	t.rotate(sib)
+}
+
+func (t *tree[K, V]) compare(a, b K) int {
+	if t.cmp == nil {
+		// Only a tree that has never been written to gets here. Don't store the
+		// natural order, since reads must never write to the tree.
+		return naturalOrder[K]()(a, b)
+	}
+	return t.cmp(a, b)
+}
+
+// Stores the natural order comparator if the tree doesn't have one yet, so that
+// later compares don't have to resolve it. Only called by writes.
+func (t *tree[K, V]) resolveComparator() {
+	if t.cmp == nil {
+		t.cmp = naturalOrder[K]()
+	}
+}
+
+func (t *tree[K, V]) Contains(obj K) bool {
 	_, nd := t.findParentAndNode(obj)
 	return nd != nil
 }
 
-func (t *Tree[T]) findParentAndNode(obj T) (*node[T], *node[T]) {
+func (t *tree[K, V]) findParentAndNode(obj K) (*node[K, V], *node[K, V]) {
 
 	nd := t.root
-	var p *node[T]
+	var p *node[K, V]
 	for nd != nil {
-		if obj < nd.data {
+		c := t.compare(obj, nd.data)
+		if c < 0 {
 			p = nd
 			nd = nd.children[0]
-		} else if obj > nd.data {
+		} else if c > 0 {
 			p = nd
 			nd = nd.children[1]
 		} else {
@@ -337,11 +682,38 @@ func (t *Tree[T]) findParentAndNode(obj T) (*node[T], *node[T]) {
 	return p, nil
 }
 
-func (t *Tree[T]) Print() {
+func (t *tree[K, V]) Len() int {
+	return t.root.getSize()
+}
+
+func (nd *node[K, V]) height() int {
+	if nd == nil {
+		return 0
+	}
+
+	h := nd.children[0].height()
+	if hr := nd.children[1].height(); hr > h {
+		h = hr
+	}
+	return h + 1
+}
+
+// Returns the number of nodes on the longest path from the root down to a leaf.
+// Takes O(n) time.
+func (t *tree[K, V]) Height() int {
+	return t.root.height()
+}
+
+// Returns the number of rotations performed since the tree was created.
+func (t *tree[K, V]) Rotations() int {
+	return t.rotations
+}
+
+func (t *tree[K, V]) Print() {
 	t.print(t.root, 0)
 }
 
-func (t *Tree[T]) print(nd *node[T], indent int) {
+func (t *tree[K, V]) print(nd *node[K, V], indent int) {
 
 	if nd == nil {
 		fmt.Printf("%s|NIL\n", strings.Repeat(" ", indent))
@@ -349,7 +721,7 @@ func (t *Tree[T]) print(nd *node[T], indent int) {
 	}
 
 	var clr string
-	if nd.color == black {
+	if nd.color == Black {
 		clr = "B"
 	} else {
 		clr = "R"
@@ -359,16 +731,45 @@ func (t *Tree[T]) print(nd *node[T], indent int) {
 	t.print(nd.children[1], indent+2)
 }
 
-func (t *Tree[T]) CheckInvariants() error {
+// Validates the structure of the tree. Returns an *InvariantError
+// describing the first violation found, or nil if the tree is valid.
+func (t *tree[K, V]) CheckInvariants() error {
+	return t.checkInvariants(true)
+}
+
+// Parent pointers are only checked when withParents is set, since the
+// nodes of persistent trees are shared between versions and have none.
+func (t *tree[K, V]) checkInvariants(withParents bool) error {
+
+	if withParents {
+		if t.root != nil && t.root.parent != nil {
+			return &InvariantError{
+				Invariant: ParentPointers,
+				Node:      t.root.data,
+				Values:    []any{t.root.data, t.root.parent.data},
+				msg:       fmt.Sprintf("root %v has parent pointer to %v", t.root.data, t.root.parent.data),
+			}
+		}
+
+		err := t.root.validateParentPointers()
+		if err != nil {
+			return err
+		}
+	}
 
 	if t.root != nil {
-		_, _, err := t.root.validateSortInvariant()
+		_, _, err := t.root.validateSortInvariant(t.compare)
 		if err != nil {
 			return err
 		}
 	}
 
-	err := t.checkColorInvariants()
+	err := t.root.validateSizeInvariant()
+	if err != nil {
+		return err
+	}
+
+	err = t.checkColorInvariants()
 	if err != nil {
 		return err
 	}
@@ -376,14 +777,18 @@ func (t *Tree[T]) CheckInvariants() error {
 	return nil
 }
 
-func (t *Tree[T]) checkColorInvariants() error {
+func (t *tree[K, V]) checkColorInvariants() error {
 
 	if t.root == nil {
 		return nil
 	}
 
-	if t.root.color != black {
-		return fmt.Errorf("root is not colored black")
+	if t.root.color != Black {
+		return &InvariantError{
+			Invariant: RootColor,
+			Node:      t.root.data,
+			msg:       "root is not colored black",
+		}
 	}
 
 	err := t.root.validateSubtreeRespectsRedColorInvariant()
@@ -391,10 +796,42 @@ func (t *Tree[T]) checkColorInvariants() error {
 		return err
 	}
 
-	err = t.root.validateSubtreeRespectsRedColorInvariant()
+	_, err = t.root.validateSubtreeRespectsBlackColorInvariant()
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// Returns a deep copy of the subtree, attached to the given parent.
+func (nd *node[K, V]) clone(parent *node[K, V]) *node[K, V] {
+
+	if nd == nil {
+		return nil
+	}
+
+	cpy := &node[K, V]{
+		parent: parent,
+		color:  nd.color,
+		size:   nd.size,
+		data:   nd.data,
+		value:  nd.value,
+	}
+	cpy.children[0] = nd.children[0].clone(cpy)
+	cpy.children[1] = nd.children[1].clone(cpy)
+	return cpy
+}
+
+func (t *tree[K, V]) clone() tree[K, V] {
+	cpy := tree[K, V]{
+		root:    t.root.clone(nil),
+		cmp:     t.cmp,
+		augment: t.augment,
+	}
+
+	// Resolve the comparator up front, so that the copy can be read from
+	// several goroutines without any of them writing to it.
+	cpy.resolveComparator()
+	return cpy
+}
@@ -0,0 +1,130 @@
+package redblacktree
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Writes the tree as a Graphviz DOT digraph, with red and black nodes, and NIL leaves.
+// Render it with e.g. "dot -Tsvg".
+func (t *tree[K, V]) WriteDOT(w io.Writer) error {
+
+	var buf bytes.Buffer
+	buf.WriteString("digraph redblacktree {\n")
+	buf.WriteString("\tnode [shape=circle, style=filled, fontcolor=white];\n")
+
+	id := 0
+	var write func(nd *node[K, V]) int
+	write = func(nd *node[K, V]) int {
+		ndID := id
+		id++
+
+		if nd == nil {
+			fmt.Fprintf(&buf, "\tn%d [label=\"NIL\", shape=box, fillcolor=black, fontsize=8, width=0.3, height=0.2];\n", ndID)
+			return ndID
+		}
+
+		fmt.Fprintf(&buf, "\tn%d [label=%s, fillcolor=%v];\n", ndID, strconv.Quote(fmt.Sprint(nd.data)), nd.color)
+		for _, child := range nd.children {
+			fmt.Fprintf(&buf, "\tn%d -> n%d;\n", ndID, write(child))
+		}
+		return ndID
+	}
+
+	if t.root != nil {
+		write(t.root)
+	}
+
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Writes the tree top-down as ASCII art. Nodes are labelled with their value and color,
+// and NIL leaves are left out.
+func (t *tree[K, V]) WriteASCII(w io.Writer) error {
+
+	lines, _, _ := renderASCII(t.root)
+
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(strings.TrimRight(line, " "))
+		buf.WriteByte('\n')
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Returns the lines drawing the subtree, all of the same width, along with the width
+// and the position of the middle of the root's label.
+func renderASCII[K any, V any](nd *node[K, V]) ([]string, int, int) {
+
+	if nd == nil {
+		return nil, 0, 0
+	}
+
+	clr := "R"
+	if nd.color == Black {
+		clr = "B"
+	}
+	label := fmt.Sprintf("%v(%s)", nd.data, clr)
+	lw := utf8.RuneCountInString(label)
+
+	left, leftW, leftMid := renderASCII(nd.children[0])
+	right, rightW, rightMid := renderASCII(nd.children[1])
+
+	// The label sits between the two subtrees, joined to their roots by underscores and slashes.
+	//
+	//	   __5(B)_
+	//	  /       \
+	//	3(R)     8(R)
+	var first, second strings.Builder
+
+	if left != nil {
+		first.WriteString(strings.Repeat(" ", leftMid+1))
+		first.WriteString(strings.Repeat("_", leftW-leftMid-1))
+		second.WriteString(strings.Repeat(" ", leftMid))
+		second.WriteString("/")
+		second.WriteString(strings.Repeat(" ", leftW-leftMid-1+lw))
+	} else {
+		second.WriteString(strings.Repeat(" ", lw))
+	}
+
+	first.WriteString(label)
+
+	if right != nil {
+		first.WriteString(strings.Repeat("_", rightMid))
+		first.WriteString(strings.Repeat(" ", rightW-rightMid))
+		second.WriteString(strings.Repeat(" ", rightMid))
+		second.WriteString("\\")
+		second.WriteString(strings.Repeat(" ", rightW-rightMid-1))
+	}
+
+	width := leftW + lw + rightW
+	mid := leftW + lw/2
+
+	if left == nil && right == nil {
+		return []string{label}, width, mid
+	}
+
+	lines := []string{first.String(), second.String()}
+
+	// Place the subtrees side by side, padding the shorter one.
+	for i := 0; i < len(left) || i < len(right); i++ {
+		l := strings.Repeat(" ", leftW)
+		if i < len(left) {
+			l = left[i]
+		}
+		r := strings.Repeat(" ", rightW)
+		if i < len(right) {
+			r = right[i]
+		}
+		lines = append(lines, l+strings.Repeat(" ", lw)+r)
+	}
+
+	return lines, width, mid
+}
@@ -0,0 +1,400 @@
+package redblacktree
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// Trees serialise the sorted sequence of their values by default. With the shape preserving
+// mode set, they serialise the exact shape and colors of their nodes instead, which helps when
+// debugging the balancing logic. Unmarshalling accepts either form, irrespective of the mode.
+
+// Sets whether marshalling preserves the exact shape and colors of the tree.
+func (t *Tree[T]) SetPreserveShape(preserve bool) {
+	t.preserveShape = preserve
+}
+
+const binaryFormatVersion = 1
+
+const (
+	binaryModeSorted byte = iota
+	binaryModeShape
+)
+
+// Markers preceding every position of a tree serialised in binary shape preserving mode.
+const (
+	binaryNil byte = iota
+	binaryRed
+	binaryBlack
+)
+
+// Returns the values of the tree in ascending order.
+func (t *Tree[T]) values() []T {
+	vals := make([]T, 0, t.Len())
+	t.Ascend(func(obj T) bool {
+		vals = append(vals, obj)
+		return true
+	})
+	return vals
+}
+
+// Replaces the contents of the tree with the given values, which must be in strictly ascending order.
+func (t *Tree[T]) setSorted(vals []T) error {
+	t.resolveComparator()
+	for i := 1; i < len(vals); i++ {
+		if t.compare(vals[i-1], vals[i]) >= 0 {
+			return fmt.Errorf("values are not in strictly ascending order: %v is followed by %v", vals[i-1], vals[i])
+		}
+	}
+	t.root = fromSorted(vals)
+	return nil
+}
+
+// Replaces the contents of the tree with a tree of the given shape, provided it's a valid one.
+func (t *Tree[T]) setShape(root *node[T, struct{}]) error {
+	t.resolveComparator()
+	candidate := t.withRoot(nil)
+	candidate.root = root
+	err := candidate.CheckInvariants()
+	if err != nil {
+		return err
+	}
+	t.root = root
+	return nil
+}
+
+// Implements encoding.BinaryMarshaler.
+//
+// The format is a version byte and a mode byte, followed by either the number of values
+// and the values in ascending order, or the nodes in pre-order with a marker for each position.
+// Values of integer, floating point and string kinds are encoded natively. Values of other types
+// need to implement encoding.BinaryMarshaler.
+func (t *Tree[T]) MarshalBinary() ([]byte, error) {
+
+	buf := []byte{binaryFormatVersion, binaryModeSorted}
+	var err error
+
+	if !t.preserveShape {
+		buf = binary.AppendUvarint(buf, uint64(t.Len()))
+		for _, val := range t.values() {
+			buf, err = appendBinaryValue(buf, val)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	}
+
+	buf[1] = binaryModeShape
+	return appendBinaryNode(buf, t.root)
+}
+
+func appendBinaryNode[T any](buf []byte, nd *node[T, struct{}]) ([]byte, error) {
+
+	if nd == nil {
+		return append(buf, binaryNil), nil
+	}
+
+	marker := binaryRed
+	if nd.color == Black {
+		marker = binaryBlack
+	}
+	buf = append(buf, marker)
+
+	buf, err := appendBinaryValue(buf, nd.data)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err = appendBinaryNode(buf, nd.children[0])
+	if err != nil {
+		return nil, err
+	}
+	return appendBinaryNode(buf, nd.children[1])
+}
+
+// Implements encoding.BinaryUnmarshaler. Replaces the contents of the tree,
+// keeping its comparator and tracer.
+func (t *Tree[T]) UnmarshalBinary(data []byte) error {
+
+	r := bytes.NewReader(data)
+
+	header := make([]byte, 2)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return fmt.Errorf("reading header: %w", err)
+	}
+
+	if header[0] != binaryFormatVersion {
+		return fmt.Errorf("unsupported format version %d", header[0])
+	}
+
+	switch header[1] {
+	case binaryModeSorted:
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return fmt.Errorf("reading number of values: %w", err)
+		}
+
+		// Every value takes at least one byte, which bounds how much to allocate up front.
+		if n > uint64(r.Len()) {
+			return fmt.Errorf("%d values do not fit in %d bytes", n, r.Len())
+		}
+
+		vals := make([]T, n)
+		for i := range vals {
+			vals[i], err = readBinaryValue[T](r)
+			if err != nil {
+				return err
+			}
+		}
+		err = t.setSorted(vals)
+		if err != nil {
+			return err
+		}
+
+	case binaryModeShape:
+		root, err := readBinaryNode[T](r, nil)
+		if err != nil {
+			return err
+		}
+		err = t.setShape(root)
+		if err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unsupported mode %d", header[1])
+	}
+
+	if r.Len() != 0 {
+		return fmt.Errorf("%d trailing bytes", r.Len())
+	}
+	return nil
+}
+
+func readBinaryNode[T any](r *bytes.Reader, parent *node[T, struct{}]) (*node[T, struct{}], error) {
+
+	marker, err := r.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("reading node marker: %w", err)
+	}
+
+	nd := &node[T, struct{}]{parent: parent}
+	switch marker {
+	case binaryNil:
+		return nil, nil
+	case binaryRed:
+		nd.color = Red
+	case binaryBlack:
+		nd.color = Black
+	default:
+		return nil, fmt.Errorf("invalid node marker %d", marker)
+	}
+
+	nd.data, err = readBinaryValue[T](r)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range nd.children {
+		nd.children[i], err = readBinaryNode(r, nd)
+		if err != nil {
+			return nil, err
+		}
+	}
+	nd.updateSize()
+	return nd, nil
+}
+
+func appendBinaryValue[T any](buf []byte, val T) ([]byte, error) {
+
+	if m, ok := any(val).(encoding.BinaryMarshaler); ok {
+		b, err := m.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buf = binary.AppendUvarint(buf, uint64(len(b)))
+		return append(buf, b...), nil
+	}
+
+	v := reflect.ValueOf(&val).Elem()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return binary.AppendVarint(buf, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return binary.AppendUvarint(buf, v.Uint()), nil
+	case reflect.Float32:
+		return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(v.Float()))), nil
+	case reflect.Float64:
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(v.Float())), nil
+	case reflect.String:
+		buf = binary.AppendUvarint(buf, uint64(v.Len()))
+		return append(buf, v.String()...), nil
+	}
+
+	return nil, fmt.Errorf("cannot encode values of type %v, which does not implement encoding.BinaryMarshaler", v.Type())
+}
+
+func readBinaryValue[T any](r *bytes.Reader) (T, error) {
+
+	var val T
+
+	readBytes := func() ([]byte, error) {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(r.Len()) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		b := make([]byte, n)
+		_, err = io.ReadFull(r, b)
+		return b, err
+	}
+
+	if u, ok := any(&val).(encoding.BinaryUnmarshaler); ok {
+		b, err := readBytes()
+		if err != nil {
+			return val, fmt.Errorf("reading value: %w", err)
+		}
+		return val, u.UnmarshalBinary(b)
+	}
+
+	v := reflect.ValueOf(&val).Elem()
+	var err error
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = binary.ReadVarint(r)
+		if err == nil && v.OverflowInt(i) {
+			err = fmt.Errorf("%d overflows %v", i, v.Type())
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		u, err = binary.ReadUvarint(r)
+		if err == nil && v.OverflowUint(u) {
+			err = fmt.Errorf("%d overflows %v", u, v.Type())
+		}
+		v.SetUint(u)
+	case reflect.Float32:
+		b := make([]byte, 4)
+		_, err = io.ReadFull(r, b)
+		v.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(b))))
+	case reflect.Float64:
+		b := make([]byte, 8)
+		_, err = io.ReadFull(r, b)
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+	case reflect.String:
+		var b []byte
+		b, err = readBytes()
+		v.SetString(string(b))
+	default:
+		return val, fmt.Errorf("cannot decode values of type %v, which does not implement encoding.BinaryUnmarshaler", v.Type())
+	}
+
+	if err != nil {
+		return val, fmt.Errorf("reading value: %w", err)
+	}
+	return val, nil
+}
+
+// Node of a tree serialised in JSON shape preserving mode.
+type jsonNode[T any] struct {
+	Value T            `json:"value"`
+	Color string       `json:"color"`
+	Left  *jsonNode[T] `json:"left"`
+	Right *jsonNode[T] `json:"right"`
+}
+
+func toJSONNode[T any](nd *node[T, struct{}]) *jsonNode[T] {
+	if nd == nil {
+		return nil
+	}
+	return &jsonNode[T]{
+		Value: nd.data,
+		Color: nd.color.String(),
+		Left:  toJSONNode(nd.children[0]),
+		Right: toJSONNode(nd.children[1]),
+	}
+}
+
+func fromJSONNode[T any](jn *jsonNode[T], parent *node[T, struct{}]) (*node[T, struct{}], error) {
+
+	if jn == nil {
+		return nil, nil
+	}
+
+	nd := &node[T, struct{}]{parent: parent, data: jn.Value}
+	switch jn.Color {
+	case Red.String():
+		nd.color = Red
+	case Black.String():
+		nd.color = Black
+	default:
+		return nil, fmt.Errorf("invalid color %q for node %v", jn.Color, jn.Value)
+	}
+
+	var err error
+	for i, child := range []*jsonNode[T]{jn.Left, jn.Right} {
+		nd.children[i], err = fromJSONNode(child, nd)
+		if err != nil {
+			return nil, err
+		}
+	}
+	nd.updateSize()
+	return nd, nil
+}
+
+// Implements json.Marshaler. The tree is serialised as an array of its values in ascending order,
+// or as nested objects with "value", "color", "left" and "right" fields in shape preserving mode.
+// An empty tree is always an empty array, since null would leave the tree it's unmarshalled into as it was.
+func (t *Tree[T]) MarshalJSON() ([]byte, error) {
+	if t.preserveShape && t.root != nil {
+		return json.Marshal(toJSONNode(t.root))
+	}
+	return json.Marshal(t.values())
+}
+
+// Implements json.Unmarshaler. Replaces the contents of the tree, keeping its comparator and tracer.
+// By convention, null is a no op.
+func (t *Tree[T]) UnmarshalJSON(data []byte) error {
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return errors.New("empty JSON input")
+	}
+
+	if bytes.Equal(trimmed, []byte("null")) {
+		return nil
+	}
+
+	if trimmed[0] == '[' {
+		var vals []T
+		err := json.Unmarshal(trimmed, &vals)
+		if err != nil {
+			return err
+		}
+		return t.setSorted(vals)
+	}
+
+	var jn *jsonNode[T]
+	err := json.Unmarshal(trimmed, &jn)
+	if err != nil {
+		return err
+	}
+
+	root, err := fromJSONNode(jn, nil)
+	if err != nil {
+		return err
+	}
+	return t.setShape(root)
+}
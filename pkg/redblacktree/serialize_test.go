@@ -0,0 +1,183 @@
+package redblacktree
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+type codec struct {
+	name      string
+	marshal   func(t *Tree[int]) ([]byte, error)
+	unmarshal func(t *Tree[int], data []byte) error
+}
+
+var codecs = []codec{
+	{
+		name:      "binary",
+		marshal:   (*Tree[int]).MarshalBinary,
+		unmarshal: (*Tree[int]).UnmarshalBinary,
+	},
+	{
+		name: "json",
+		marshal: func(t *Tree[int]) ([]byte, error) {
+			return json.Marshal(t)
+		},
+		unmarshal: func(t *Tree[int], data []byte) error {
+			return json.Unmarshal(data, t)
+		},
+	},
+}
+
+// Returns the shape preserving JSON encoding of the tree, to compare shapes with.
+func shapeOf(t *testing.T, tr *Tree[int]) string {
+	t.Helper()
+
+	tr.SetPreserveShape(true)
+	defer tr.SetPreserveShape(false)
+
+	data, err := json.Marshal(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func roundTrip(t *testing.T, c codec, tr *Tree[int], into *Tree[int], preserveShape bool) {
+	t.Helper()
+
+	tr.SetPreserveShape(preserveShape)
+	defer tr.SetPreserveShape(false)
+
+	data, err := c.marshal(tr)
+	if err != nil {
+		t.Fatalf("%s marshal: %v", c.name, err)
+	}
+
+	err = c.unmarshal(into, data)
+	if err != nil {
+		t.Fatalf("%s unmarshal: %v", c.name, err)
+	}
+
+	err = into.CheckInvariants()
+	if err != nil {
+		t.Fatalf("%s round trip: %v", c.name, err)
+	}
+
+	if !equalInts(values(into), values(tr)) {
+		t.Fatalf("%s round trip gave %v, expected %v", c.name, values(into), values(tr))
+	}
+
+	if preserveShape && shapeOf(t, into) != shapeOf(t, tr) {
+		t.Fatalf("%s round trip changed the shape from %s to %s", c.name, shapeOf(t, tr), shapeOf(t, into))
+	}
+}
+
+func TestSerializeRoundTrip(t *testing.T) {
+
+	const maxItems = 50
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 100; i++ {
+		tr := &Tree[int]{}
+		for _, val := range r.Perm(r.Intn(maxItems + 1)) {
+			tr.Insert(val - maxItems/2)
+			if r.Intn(4) == 0 {
+				tr.Delete(r.Intn(maxItems) - maxItems/2)
+			}
+		}
+
+		for _, c := range codecs {
+			roundTrip(t, c, tr, &Tree[int]{}, false)
+			roundTrip(t, c, tr, &Tree[int]{}, true)
+		}
+	}
+}
+
+// Unmarshalling replaces the contents of the tree, even when there's nothing to replace them with.
+func TestSerializeEmptyIntoNonEmpty(t *testing.T) {
+	for _, c := range codecs {
+		for _, preserveShape := range []bool{false, true} {
+			roundTrip(t, c, &Tree[int]{}, FromSorted([]int{1, 2, 3}), preserveShape)
+		}
+	}
+}
+
+// Comparators survive unmarshalling into an existing tree.
+func TestSerializeKeepsComparator(t *testing.T) {
+
+	reverse := func(a, b string) int {
+		return -strings.Compare(a, b)
+	}
+
+	reversed := NewWithComparator(reverse)
+	for _, s := range []string{"kiwi", "apple", "mango", "fig"} {
+		reversed.Insert(s)
+	}
+	data, err := json.Marshal(reversed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := NewWithComparator(reverse)
+	err = json.Unmarshal(data, decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.CheckInvariants(); err != nil {
+		t.Fatal(err)
+	}
+
+	if json.Unmarshal(data, &Tree[string]{}) == nil {
+		t.Errorf("reverse ordered strings %s were accepted into a naturally ordered tree", data)
+	}
+}
+
+// Malformed input never makes it into a tree.
+func TestSerializeRejectsMalformed(t *testing.T) {
+
+	good := FromSorted([]int{1, 2, 3, 4, 5})
+	data, err := good.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		unmarshal func(t *Tree[int]) error
+	}{
+		{"unsorted values", func(t *Tree[int]) error {
+			return json.Unmarshal([]byte(`[1, 3, 2]`), t)
+		}},
+		{"red root", func(t *Tree[int]) error {
+			return json.Unmarshal([]byte(`{"value": 1, "color": "red", "left": null, "right": null}`), t)
+		}},
+		{"unknown color", func(t *Tree[int]) error {
+			return json.Unmarshal([]byte(`{"value": 1, "color": "blue", "left": null, "right": null}`), t)
+		}},
+		{"truncated binary", func(t *Tree[int]) error {
+			return t.UnmarshalBinary(data[:len(data)-1])
+		}},
+		{"binary with trailing bytes", func(t *Tree[int]) error {
+			return t.UnmarshalBinary(append(bytes.Clone(data), 0))
+		}},
+	}
+
+	for _, test := range tests {
+		if test.unmarshal(&Tree[int]{}) == nil {
+			t.Errorf("%s was accepted", test.name)
+		}
+	}
+}
+
+// By convention, unmarshalling null leaves the tree as it was.
+func TestUnmarshalJSONNull(t *testing.T) {
+
+	tr := FromSorted([]int{1, 2, 3, 4, 5})
+	err := json.Unmarshal([]byte("null"), tr)
+	if err != nil || tr.Len() != 5 {
+		t.Errorf("unmarshalling null returned %v and left %d values, expected 5", err, tr.Len())
+	}
+}
@@ -0,0 +1,335 @@
+package redblacktree
+
+import "math/bits"
+
+// Returns the number of black nodes on any path from the node down to a NIL leaf.
+func (nd *node[K, V]) blackHeight() int {
+	bh := 0
+	for ; nd != nil; nd = nd.children[0] {
+		if nd.color == Black {
+			bh++
+		}
+	}
+	return bh
+}
+
+// Detaches the node from its parent and children, and returns the children as standalone subtrees.
+func (nd *node[K, V]) detach() (*node[K, V], *node[K, V]) {
+	l, r := nd.children[0], nd.children[1]
+	if l != nil {
+		l.parent = nil
+	}
+	if r != nil {
+		r.parent = nil
+	}
+
+	nd.parent = nil
+	nd.children = [2]*node[K, V]{nil, nil}
+	nd.size = 1
+	return l, r
+}
+
+// Returns a tree rooted at root, sharing the comparator and tracer of t.
+func (t *tree[K, V]) withRoot(root *node[K, V]) *tree[K, V] {
+	s := &tree[K, V]{cmp: t.cmp, tracer: t.tracer, augment: t.augment}
+	s.setRoot(root)
+	return s
+}
+
+// Makes the standalone subtree the whole tree. A subtree may have a red root,
+// and it's always safe to make the root node black.
+func (t *tree[K, V]) setRoot(root *node[K, V]) {
+	t.resolveComparator()
+	t.root = root
+	if root != nil {
+		t.recolor(root, Black)
+	}
+}
+
+// Adds the rotations, recolors and rebalancing steps performed on s, a temporary tree
+// rooted at one of t's standalone subtrees, to t's own counters.
+func (t *tree[K, V]) addCounters(s *tree[K, V]) {
+	t.rotations += s.rotations
+	t.recolors += s.recolors
+	t.rebalanceSteps += s.rebalanceSteps
+}
+
+// Joins the standalone subtrees l and r, and the detached node nd, into a single tree
+// and returns its root. All keys in l must be smaller than nd's, and all keys in r larger.
+// Takes O(|bh(l) - bh(r)| + 1) time.
+func (t *tree[K, V]) join(l *node[K, V], nd *node[K, V], r *node[K, V]) *node[K, V] {
+
+	// It's always safe to make the root node black.
+	for _, root := range []*node[K, V]{l, r} {
+		if root != nil {
+			t.recolor(root, Black)
+		}
+	}
+
+	bhl, bhr := l.blackHeight(), r.blackHeight()
+
+	if bhl == bhr {
+		// Both subtrees can hang directly off the node.
+		nd.children = [2]*node[K, V]{l, r}
+		for _, child := range nd.children {
+			if child != nil {
+				child.parent = nd
+			}
+		}
+		nd.color = Black
+		nd.updateSize()
+		return nd
+	}
+
+	// Walk down the spine of the taller subtree that faces the shorter one, until
+	// reaching a black node (or NIL) with the same black height as the shorter subtree.
+	dir, tall, short, bhShort := 1, l, r, bhr
+	bh := bhl
+	if bhr > bhl {
+		dir, tall, short, bhShort = 0, r, l, bhl
+		bh = bhr
+	}
+
+	var p *node[K, V]
+	c := tall
+	for !(bh == bhShort && (c == nil || c.color == Black)) {
+		if c.color == Black {
+			bh--
+		}
+		p = c
+		c = c.children[dir]
+	}
+
+	// Put the node in the place of c, with c and the shorter subtree as its children.
+	// Coloring it red keeps the black heights intact, but may cause a double red problem.
+	nd.children[1-dir] = c
+	nd.children[dir] = short
+	for _, child := range nd.children {
+		if child != nil {
+			child.parent = nd
+		}
+	}
+	p.children[dir] = nd
+	nd.parent = p
+	nd.color = Red
+	nd.updateSize()
+	p.adjustSizes(1 + short.getSize())
+
+	joined := &tree[K, V]{root: tall, cmp: t.cmp, tracer: t.tracer, augment: t.augment}
+	joined.rebalance(nd)
+	t.addCounters(joined)
+	return joined.root
+}
+
+// Joins the standalone subtrees l and r into a single tree and returns its root.
+// All keys in l must be smaller than the keys in r.
+func (t *tree[K, V]) join2(l *node[K, V], r *node[K, V]) *node[K, V] {
+
+	if l == nil {
+		return r
+	}
+	if r == nil {
+		return l
+	}
+
+	// Take the smallest node out of r, and use it to join the two.
+	rest := &tree[K, V]{root: r, cmp: t.cmp, tracer: t.tracer, augment: t.augment}
+	min := r.extreme(0)
+	rest.removeNode(min)
+	t.addCounters(rest)
+
+	// The smallest node has no left child, so it's the node removeNode detached from the tree.
+	// It may still point to its old right child, which has since moved up in its place.
+	min.children = [2]*node[K, V]{nil, nil}
+	min.size = 1
+	return t.join(l, min, rest.root)
+}
+
+// Splits the standalone subtree rooted at nd into the subtree with keys smaller than obj,
+// the node holding obj if any, and the subtree with keys larger than obj.
+// Takes O(log n) time.
+func (t *tree[K, V]) split(nd *node[K, V], obj K) (*node[K, V], *node[K, V], *node[K, V]) {
+
+	if nd == nil {
+		return nil, nil, nil
+	}
+
+	l, r := nd.detach()
+
+	c := t.compare(obj, nd.data)
+	if c == 0 {
+		return l, nd, r
+	}
+
+	if c < 0 {
+		ll, mid, lr := t.split(l, obj)
+		return ll, mid, t.join(lr, nd, r)
+	}
+
+	rl, mid, rr := t.split(r, obj)
+	return t.join(l, nd, rl), mid, rr
+}
+
+func (t *tree[K, V]) union(a *node[K, V], b *node[K, V]) *node[K, V] {
+
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	al, ar := a.detach()
+	bl, _, br := t.split(b, a.data)
+	return t.join(t.union(al, bl), a, t.union(ar, br))
+}
+
+func (t *tree[K, V]) intersection(a *node[K, V], b *node[K, V]) *node[K, V] {
+
+	if a == nil || b == nil {
+		return nil
+	}
+
+	al, ar := a.detach()
+	bl, mid, br := t.split(b, a.data)
+	l := t.intersection(al, bl)
+	r := t.intersection(ar, br)
+	if mid == nil {
+		return t.join2(l, r)
+	}
+	return t.join(l, a, r)
+}
+
+func (t *tree[K, V]) difference(a *node[K, V], b *node[K, V]) *node[K, V] {
+
+	if a == nil || b == nil {
+		return a
+	}
+
+	bl, br := b.detach()
+	al, _, ar := t.split(a, b.data)
+	return t.join2(t.difference(al, bl), t.difference(ar, br))
+}
+
+// Builds a subtree out of the sorted values in O(n) time. Nodes at the given depth are red,
+// and all others are black.
+func buildSorted[T any](vals []T, depth int, redDepth int) *node[T, struct{}] {
+
+	if len(vals) == 0 {
+		return nil
+	}
+
+	mid := len(vals) / 2
+	nd := &node[T, struct{}]{
+		color: Black,
+		size:  len(vals),
+		data:  vals[mid],
+	}
+	if depth == redDepth {
+		nd.color = Red
+	}
+
+	nd.children[0] = buildSorted(vals[:mid], depth+1, redDepth)
+	nd.children[1] = buildSorted(vals[mid+1:], depth+1, redDepth)
+	for _, child := range nd.children {
+		if child != nil {
+			child.parent = nd
+		}
+	}
+	return nd
+}
+
+// Creates a tree holding the given values, ordered by their natural order, in O(n) time.
+// The values must be in strictly ascending order.
+func FromSorted[T any](vals []T) *Tree[T] {
+	t := &Tree[T]{}
+	t.resolveComparator()
+	t.root = fromSorted(vals)
+	return t
+}
+
+// Creates a tree holding the given values, ordered using cmp, in O(n) time.
+// The values must be in strictly ascending order according to cmp.
+func FromSortedWithComparator[T any](vals []T, cmp func(a, b T) int) *Tree[T] {
+	t := NewWithComparator(cmp)
+	t.root = fromSorted(vals)
+	return t
+}
+
+func fromSorted[T any](vals []T) *node[T, struct{}] {
+
+	// Splitting the values evenly puts every NIL leaf at the deepest level
+	// or the one above it. Coloring the nodes at the deepest level red
+	// evens out the black depths.
+	root := buildSorted(vals, 0, bits.Len(uint(len(vals)))-1)
+	if root != nil {
+		root.color = Black
+	}
+	return root
+}
+
+// Returns an independent copy of the tree.
+func (t *Tree[T]) Clone() *Tree[T] {
+	return &Tree[T]{tree: t.clone()}
+}
+
+// Moves the values smaller than pivot into one tree, and the values larger than pivot into another.
+// Reports whether pivot was in the tree. Leaves the tree empty. Takes O(log n) time.
+func (t *Tree[T]) Split(pivot T) (*Tree[T], bool, *Tree[T]) {
+	t.resolveComparator()
+	l, mid, r := t.split(t.root, pivot)
+	t.root = nil
+	return &Tree[T]{tree: *t.withRoot(l)}, mid != nil, &Tree[T]{tree: *t.withRoot(r)}
+}
+
+// Moves all the values of other into the tree. All values in other must be larger than
+// the values in the tree. Leaves other empty. Takes O(log n) time. Panics if other is the
+// tree itself, unless it's empty.
+func (t *Tree[T]) Join(other *Tree[T]) {
+	if other == t {
+		if t.root != nil {
+			panic("redblacktree: can't join a non-empty tree with itself")
+		}
+		return
+	}
+	t.resolveComparator()
+	t.setRoot(t.join2(t.root, other.root))
+	other.root = nil
+}
+
+// Makes the tree hold the values that are in either the tree or other. Leaves other empty.
+// Takes O(m log(n/m + 1)) time, where m is the size of the smaller tree. Use Clone to keep other.
+// The union of a tree with itself leaves it as it is.
+func (t *Tree[T]) Union(other *Tree[T]) {
+	if other == t {
+		return
+	}
+	t.resolveComparator()
+	t.setRoot(t.union(t.root, other.root))
+	other.root = nil
+}
+
+// Makes the tree hold the values that are in both the tree and other. Leaves other empty.
+// Takes O(m log(n/m + 1)) time, where m is the size of the smaller tree. Use Clone to keep other.
+// The intersection of a tree with itself leaves it as it is.
+func (t *Tree[T]) Intersection(other *Tree[T]) {
+	if other == t {
+		return
+	}
+	t.resolveComparator()
+	t.setRoot(t.intersection(t.root, other.root))
+	other.root = nil
+}
+
+// Makes the tree hold the values that are in the tree but not in other. Leaves other empty.
+// Takes O(m log(n/m + 1)) time, where m is the size of the smaller tree. Use Clone to keep other.
+// The difference of a tree with itself empties it.
+func (t *Tree[T]) Difference(other *Tree[T]) {
+	if other == t {
+		t.root = nil
+		return
+	}
+	t.resolveComparator()
+	t.setRoot(t.difference(t.root, other.root))
+	other.root = nil
+}
@@ -0,0 +1,82 @@
+package redblacktree
+
+import "testing"
+
+func values(t *Tree[int]) []int {
+	vals := []int{}
+	t.Ascend(func(val int) bool {
+		vals = append(vals, val)
+		return true
+	})
+	return vals
+}
+
+func equalInts(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Set operations with the tree itself as other mustn't empty the tree by leaving other empty.
+func TestSetOpsWithSelf(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		op       func(t *Tree[int])
+		expected []int
+	}{
+		{"Union", func(t *Tree[int]) { t.Union(t) }, []int{1, 2, 3, 4, 5}},
+		{"Intersection", func(t *Tree[int]) { t.Intersection(t) }, []int{1, 2, 3, 4, 5}},
+		{"Difference", func(t *Tree[int]) { t.Difference(t) }, []int{}},
+	}
+
+	for _, test := range tests {
+		tr := FromSorted([]int{1, 2, 3, 4, 5})
+		test.op(tr)
+
+		if got := values(tr); !equalInts(got, test.expected) || tr.Len() != len(test.expected) {
+			t.Errorf("%s with self left %v, expected %v", test.name, got, test.expected)
+		}
+		if err := tr.CheckInvariants(); err != nil {
+			t.Errorf("%s with self: %v", test.name, err)
+		}
+	}
+}
+
+func TestJoinWithSelf(t *testing.T) {
+
+	empty := &Tree[int]{}
+	empty.Join(empty)
+	if empty.Len() != 0 {
+		t.Errorf("joining an empty tree with itself left %d values", empty.Len())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("joining a non-empty tree with itself didn't panic")
+		}
+	}()
+	tr := FromSorted([]int{1, 2, 3})
+	tr.Join(tr)
+}
+
+// A tree built by FromSorted resolves its natural order up front, like any other write,
+// so that compares don't have to resolve it again every time.
+func TestFromSortedResolvesComparator(t *testing.T) {
+
+	type id int
+
+	tr := FromSorted([]id{1, 2, 3, 4, 5})
+	allocs := testing.AllocsPerRun(100, func() {
+		tr.Contains(3)
+	})
+	if allocs != 0 {
+		t.Errorf("Contains on a tree built by FromSorted made %v allocations, expected none", allocs)
+	}
+}
@@ -0,0 +1,33 @@
+package redblacktree
+
+// Stats describes the shape of a tree, and how much work it has done to keep itself balanced.
+type Stats struct {
+	// Number of nodes in the tree.
+	Len int
+
+	// Number of nodes on the longest path from the root down to a leaf.
+	Height int
+
+	// Number of black nodes on any path from the root down to a leaf.
+	BlackHeight int
+
+	// Cumulative counts since the tree was created.
+	Rotations int
+	Recolors  int
+
+	// Number of fix-up steps taken to restore the red-black invariants after inserts and deletes.
+	// Each step handles one double red or double black problem at one level of the tree.
+	RebalanceSteps int
+}
+
+// Returns the current statistics of the tree. Takes O(n) time.
+func (t *tree[K, V]) Stats() Stats {
+	return Stats{
+		Len:            t.Len(),
+		Height:         t.Height(),
+		BlackHeight:    t.root.blackHeight(),
+		Rotations:      t.rotations,
+		Recolors:       t.recolors,
+		RebalanceSteps: t.rebalanceSteps,
+	}
+}
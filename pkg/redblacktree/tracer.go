@@ -0,0 +1,22 @@
+package redblacktree
+
+// Tracer observes the changes a tree makes to its structure while
+// keeping itself balanced. Trees don't trace anything unless a tracer is set.
+type Tracer[K any] interface {
+	// Called when obj is about to be inserted into the tree.
+	OnInsert(obj K)
+
+	// Called when obj is about to be deleted from the tree.
+	OnDelete(obj K)
+
+	// Called when the node holding obj is rotated above its parent.
+	OnRotate(obj K)
+
+	// Called when the node holding obj changes color to c.
+	OnRecolor(obj K, c Color)
+}
+
+// Sets the tracer observing the tree. A nil tracer turns tracing off.
+func (t *tree[K, V]) SetTracer(tracer Tracer[K]) {
+	t.tracer = tracer
+}
@@ -0,0 +1,188 @@
+// Package semaphore implements a counting semaphore in the style of Downey's
+// "The Little Book of Semaphores", with weighted acquires and context-aware waiting.
+package semaphore
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// A goroutine blocked in Acquire, waiting for n units.
+type waiter struct {
+	n int
+
+	// Closed once the units have been handed to the waiter.
+	ready chan struct{}
+}
+
+// Semaphore is a counting semaphore. Its value is never bounded from above, so signalling
+// never blocks. Waiters are served in FIFO order. A waiter asking for more units than are
+// available blocks every waiter behind it, even those that could be satisfied, so that
+// large acquires don't starve.
+type Semaphore struct {
+	mu sync.Mutex
+
+	value int
+
+	waiters list.List
+}
+
+// Creates a new semaphore with the given initial value. Panics if initial is negative.
+func New(initial int) *Semaphore {
+	if initial < 0 {
+		panic("semaphore: initial value should be non-negative")
+	}
+	return &Semaphore{value: initial}
+}
+
+// Decrements the semaphore, blocking until that's possible.
+func (s *Semaphore) Wait() {
+	s.Acquire(1)
+}
+
+// Decrements the semaphore if that's possible without blocking. Returns whether it did.
+func (s *Semaphore) TryWait() bool {
+	return s.TryAcquire(1)
+}
+
+// Decrements the semaphore, blocking until that's possible or ctx is done.
+// Returns ctx.Err() if ctx is done first, in which case the semaphore is left unchanged.
+func (s *Semaphore) WaitContext(ctx context.Context) error {
+	return s.AcquireContext(ctx, 1)
+}
+
+// Decrements the semaphore, blocking for at most d. Returns whether it did.
+func (s *Semaphore) WaitTimeout(d time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return s.AcquireContext(ctx, 1) == nil
+}
+
+// Increments the semaphore, waking up a waiter if there is one.
+func (s *Semaphore) Signal() {
+	s.Release(1)
+}
+
+// Increments the semaphore by n.
+func (s *Semaphore) SignalN(n int) {
+	s.Release(n)
+}
+
+// Decrements the semaphore by n, blocking until that's possible.
+func (s *Semaphore) Acquire(n int) {
+	// Can't fail, since the background context is never done.
+	_ = s.AcquireContext(context.Background(), n)
+}
+
+// Decrements the semaphore by n if that's possible without blocking. Returns whether it did.
+func (s *Semaphore) TryAcquire(n int) bool {
+	checkWeight(n)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.waiters.Len() == 0 && s.value >= n {
+		s.value -= n
+		return true
+	}
+	return false
+}
+
+// Decrements the semaphore by n, blocking until that's possible or ctx is done.
+// Returns ctx.Err() if ctx is done first, in which case the semaphore is left unchanged.
+func (s *Semaphore) AcquireContext(ctx context.Context, n int) error {
+	checkWeight(n)
+
+	s.mu.Lock()
+
+	// Only take the fast path if nobody is queued up ahead of us.
+	if s.waiters.Len() == 0 && s.value >= n {
+		s.value -= n
+		s.mu.Unlock()
+		return nil
+	}
+
+	w := &waiter{n: n, ready: make(chan struct{})}
+	el := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		// The units were handed to us after ctx was done, but before we got the lock.
+		// Give them back.
+		s.value += n
+	default:
+		s.waiters.Remove(el)
+	}
+
+	// Either way, the waiters behind us may now be able to go ahead.
+	s.notifyWaiters()
+	return ctx.Err()
+}
+
+// Increments the semaphore by n, waking up as many waiters, in order, as can now go ahead.
+func (s *Semaphore) Release(n int) {
+	checkWeight(n)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.value += n
+	s.notifyWaiters()
+}
+
+// Returns the current value of the semaphore. It may already be stale by the time it's returned,
+// so this is only useful for monitoring.
+func (s *Semaphore) Value() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.value
+}
+
+// Returns the number of goroutines currently blocked waiting on the semaphore.
+// Like Value, it's only useful for monitoring.
+func (s *Semaphore) Waiters() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.waiters.Len()
+}
+
+func (s *Semaphore) notifyWaiters() {
+	// Must be called with the lock held.
+
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+
+		w := front.Value.(*waiter)
+		if s.value < w.n {
+			// Not enough for the waiter at the front. Nobody behind it gets to jump the queue.
+			return
+		}
+
+		s.value -= w.n
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
+
+func checkWeight(n int) {
+	if n < 0 {
+		panic("semaphore: weight should be non-negative")
+	}
+}